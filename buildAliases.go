@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Alias holds an old URL that redirects to the permalink of a page
+type Alias struct {
+	From string // The old URL
+	To   string // The permalink of the page
+	File string // The content file the alias belongs to
+}
+
+// The redirect map files written to the output directory
+const (
+	netlifyRedirectsFile = "_redirects"
+	nginxRedirectsFile   = "redirects.nginx.conf"
+)
+
+// The page written at each alias, redirecting to the page with a meta refresh
+// and pointing search engines to the page with a canonical link
+var aliasTemplate = template.Must(template.New("alias").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <title>{{ .To }}</title>
+    <link rel="canonical" href="{{ .Canonical }}">
+    <meta name="robots" content="noindex">
+    <meta charset="utf-8">
+    <meta http-equiv="refresh" content="0; url={{ .To }}">
+</head>
+<body>
+    <p>This page has moved to <a href="{{ .To }}">{{ .To }}</a>.</p>
+</body>
+</html>
+`))
+
+// **********  Private Alias Methods  **********
+
+// Write a redirect page for each alias in the front matter, and the redirect
+// maps for Netlify and nginx from the same aliases
+func (b *Builder) buildAliases(dirsMap map[string]DirectoryInfo) error {
+	aliases, err := b.collectAliases(dirsMap)
+	if err != nil {
+		return err
+	}
+	if len(aliases) == 0 {
+		return nil
+	}
+	logger.Info("Building %d redirects", len(aliases))
+
+	for _, alias := range aliases {
+		outputPath := b.outputFilePath(alias.From)
+		if filesystem.Exists(outputPath) {
+			err := fmt.Errorf("alias %s would replace an existing page", alias.From)
+			if err := b.addError(alias.File, "", err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var buf bytes.Buffer
+		data := map[string]string{"To": alias.To, "Canonical": absoluteURL(alias.To)}
+		if err := aliasTemplate.Execute(&buf, data); err != nil {
+			return err
+		}
+		if err := filesystem.Create(outputPath, buf.String()); err != nil {
+			return err
+		}
+	}
+
+	// filesystem.Create treats paths without an extension as directories
+	if err := os.WriteFile(filepath.Join(b.outputDir, netlifyRedirectsFile), []byte(netlifyRedirects(aliases)), 0644); err != nil {
+		return err
+	}
+	return filesystem.Create(filepath.Join(b.outputDir, nginxRedirectsFile), nginxRedirects(aliases))
+}
+
+// Collect the aliases of every file, sorted by the old URL
+// An alias used by more than one file is an error
+func (b *Builder) collectAliases(dirsMap map[string]DirectoryInfo) ([]Alias, error) {
+	var aliases []Alias
+	for _, dirInfo := range dirsMap {
+		for _, file := range dirInfo.Files {
+			for _, from := range metaStrings(file.MetaData, "aliases") {
+				aliases = append(aliases, Alias{
+					From: normalizePermalink(from),
+					To:   file.OutputPath,
+					File: file.Path,
+				})
+			}
+		}
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		if aliases[i].From != aliases[j].From {
+			return aliases[i].From < aliases[j].From
+		}
+		return aliases[i].File < aliases[j].File
+	})
+
+	// Drop aliases that point to the page itself or are used twice
+	var unique []Alias
+	for i, alias := range aliases {
+		if alias.From == alias.To {
+			continue
+		}
+		if i > 0 && aliases[i-1].From == alias.From {
+			err := fmt.Errorf("alias %s is also used by %s", alias.From, aliases[i-1].File)
+			if err := b.addError(alias.File, "", err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		unique = append(unique, alias)
+	}
+
+	return unique, nil
+}
+
+// Returns the Netlify _redirects file for the aliases
+func netlifyRedirects(aliases []Alias) string {
+	var builder strings.Builder
+	builder.WriteString("# Generated by Repose from the aliases in the content front matter\n")
+	for _, alias := range aliases {
+		fmt.Fprintf(&builder, "%s %s 301\n", alias.From, alias.To)
+	}
+	return builder.String()
+}
+
+// Returns an nginx map of the aliases. Include it in the http block and
+// redirect in the server block with:
+//
+//	if ($repose_redirect) { return 301 $repose_redirect; }
+func nginxRedirects(aliases []Alias) string {
+	var builder strings.Builder
+	builder.WriteString("# Generated by Repose from the aliases in the content front matter\n")
+	builder.WriteString("# Include in the http block and add this to the server block:\n")
+	builder.WriteString("#   if ($repose_redirect) { return 301 $repose_redirect; }\n")
+	builder.WriteString("map $uri $repose_redirect {\n")
+	for _, alias := range aliases {
+		fmt.Fprintf(&builder, "    %s %s;\n", alias.From, alias.To)
+		// Pretty URLs are also requested without the trailing slash
+		if from := strings.TrimSuffix(alias.From, "/"); from != alias.From && from != "" {
+			fmt.Fprintf(&builder, "    %s %s;\n", from, alias.To)
+		}
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
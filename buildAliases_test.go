@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuilder_CollectAliases(t *testing.T) {
+	restoreConfig(t)
+	config.UglyURLs = false
+	b := Builder{}
+	dirsMap := map[string]DirectoryInfo{
+		"content/post": {Files: []FileInfo{
+			{Path: "post/new.md", OutputPath: "/post/new/", MetaData: map[string]interface{}{
+				"aliases": []interface{}{"/old/post", "/2019/old.html", "/post/new/"},
+			}},
+			{Path: "post/other.md", OutputPath: "/post/other/", MetaData: map[string]interface{}{
+				"aliases": "/old/post/",
+			}},
+		}},
+	}
+
+	aliases, err := b.collectAliases(dirsMap)
+	if err != nil {
+		t.Fatalf("collectAliases returned an error: %v", err)
+	}
+
+	// The alias to the page itself is dropped, and the second use of an alias is an error
+	want := []Alias{
+		{From: "/2019/old.html", To: "/post/new/", File: "post/new.md"},
+		{From: "/old/post/", To: "/post/new/", File: "post/new.md"},
+	}
+	if len(aliases) != len(want) {
+		t.Fatalf("Alias count mismatch. Got: %v, Want: %v", aliases, want)
+	}
+	for i := range want {
+		if aliases[i] != want[i] {
+			t.Errorf("Alias mismatch. Got: %v, Want: %v", aliases[i], want[i])
+		}
+	}
+	if len(b.errors) != 1 || b.errors[0].File != "post/other.md" {
+		t.Errorf("Expected a duplicate alias error for post/other.md. Got: %v", b.errors)
+	}
+
+	if redirects := netlifyRedirects(aliases); !strings.Contains(redirects, "/old/post/ /post/new/ 301\n") {
+		t.Errorf("Netlify redirects mismatch. Got: %q", redirects)
+	}
+}
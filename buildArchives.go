@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+)
+
+// ArchiveConfig controls the date archive pages
+type ArchiveConfig struct {
+	// Sections lists the sections with archive pages for each year and month,
+	// e.g. /post/2024/ and /post/2024/01/ - none by default, so existing sites
+	// don't gain archive pages until they opt in
+	Sections []string `yaml:"sections"`
+	// Path is the permalink of the archive of every section, e.g. /archive/
+	// Leave it empty for no overall archive
+	Path string `yaml:"path"`
+}
+
+// Pages is a list of pages, used in templates as .Site.Pages and .Files
+type Pages []FileInfo
+
+// PageGroup is a group of pages that share a key, e.g. the year they were published
+type PageGroup struct {
+	Key   string // The key of the group, e.g. 2024
+	Pages Pages  // The pages in the group, newest first
+}
+
+// The template used for the archive pages, list.tmpl is used when a site doesn't have one
+const archiveTemplate = "archive.tmpl"
+
+// The template used for list pages when a site doesn't have a more specific one
+const listTemplate = "list.tmpl"
+
+// **********  Public Pages Methods  **********
+
+// GroupByDate groups the pages by their date in the given layout, newest first.
+// Used in templates as {{ range .Site.Pages.GroupByDate "2006" }}
+func (p Pages) GroupByDate(layout string) []PageGroup {
+	var groups []PageGroup
+	index := make(map[string]int)
+	for _, page := range p.ByDate() {
+		key := page.Date.Format(layout)
+		i, exists := index[key]
+		if !exists {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, PageGroup{Key: key})
+		}
+		groups[i].Pages = append(groups[i].Pages, page)
+	}
+	return groups
+}
+
+// ByDate returns the pages sorted newest first, then by permalink
+func (p Pages) ByDate() Pages {
+	sorted := append(Pages{}, p...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Date.Equal(sorted[j].Date) {
+			return sorted[i].Date.After(sorted[j].Date)
+		}
+		return sorted[i].OutputPath < sorted[j].OutputPath
+	})
+	return sorted
+}
+
+// **********  Private Archive Methods  **********
+
+// Write the archive pages for the dated pages in each archived section: one
+// for each year and month of the section, and an overall archive for each
+// language. Pages are dated by publish_date (or date) in their front matter.
+func (b *Builder) buildArchives(dirsMap map[string]DirectoryInfo) error {
+	if len(config.Archives.Sections) == 0 {
+		return nil
+	}
+	logger.Info("Building archive pages")
+
+	for _, lang := range languageCodes() {
+		var all Pages
+		for _, section := range config.Archives.Sections {
+			var pages Pages
+			for _, file := range sortedFiles(dirsMap) {
+				if file.Language == lang && file.ContentType == section && file.Name != "index" && hasDate(file) {
+					pages = append(pages, file)
+				}
+			}
+			all = append(all, pages...)
+
+			for _, year := range pages.GroupByDate("2006") {
+				dir := path.Join(languageDir(lang), section, year.Key)
+				if err := b.writeListPage(dir, archiveTemplate, lang, year.Key, year.Pages); err != nil {
+					return err
+				}
+				for _, month := range year.Pages.GroupByDate("01") {
+					title := month.Pages[0].Date.Format("January 2006")
+					if err := b.writeListPage(path.Join(dir, month.Key), archiveTemplate, lang, title, month.Pages); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if config.Archives.Path != "" && len(all) > 0 {
+			dir := path.Join(languageDir(lang), config.Archives.Path)
+			if err := b.writeListPage(dir, archiveTemplate, lang, "Archive", all.ByDate()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Render a list page of the given pages at the directory, e.g. an archive
+func (b *Builder) writeListPage(dir string, templateFile string, lang string, title string, pages Pages) error {
+	pageData := PageData{
+		SiteName: config.Sitename,
+		Logo:     logo50,
+		Title:    title,
+		Page: FileInfo{
+			Path:       dir,
+			OutputPath: listPermalink(dir),
+			Language:   lang,
+			MetaData:   map[string]interface{}{"title": title},
+		},
+		Files: pages,
+		Site:  b.sites[lang],
+	}
+	return b.writePage(templateFile, pageData)
+}
+
+// Render a page that isn't in the content directory and write it to its permalink
+// The list template is used when the site doesn't have the given template.
+func (b *Builder) writePage(templateFile string, pageData PageData) error {
+	if b.templates.Lookup(templateFile) == nil && b.layouts[templateFile].Source == "" {
+		templateFile = listTemplate
+	}
+
+	permalink := pageData.Page.OutputPath
+	output, err := b.renderPage(templateFile, pageData, pageData)
+	if err == nil {
+		err = filesystem.Create(b.outputFilePath(permalink), output)
+	}
+	if err != nil {
+		return b.addError(pageData.Page.Path, templateFile, fmt.Errorf("error writing page %s: %w", permalink, err))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPages_GroupByDate(t *testing.T) {
+	pages := Pages{
+		{OutputPath: "/b/", Date: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{OutputPath: "/c/", Date: time.Date(2023, 12, 5, 0, 0, 0, 0, time.UTC)},
+		{OutputPath: "/a/", Date: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{OutputPath: "/d/", Date: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)},
+	}
+
+	years := pages.GroupByDate("2006")
+	if len(years) != 2 || years[0].Key != "2024" || years[1].Key != "2023" {
+		t.Fatalf("Expected 2024 then 2023. Got: %v", years)
+	}
+	months := years[0].Pages.GroupByDate("2006-01")
+	if len(months) != 2 || months[0].Key != "2024-03" || len(months[1].Pages) != 2 {
+		t.Fatalf("Expected March then two pages in January. Got: %v", months)
+	}
+	// Pages on the same day are sorted by permalink
+	if months[1].Pages[0].OutputPath != "/b/" || months[1].Pages[1].OutputPath != "/d/" {
+		t.Errorf("Page order mismatch. Got: %v", months[1].Pages)
+	}
+}
+
+func TestBuilder_BuildArchives(t *testing.T) {
+	restoreConfig(t)
+	root := t.TempDir()
+	templateDir := filepath.Join(root, "template")
+	os.MkdirAll(templateDir, 0755)
+	os.WriteFile(filepath.Join(templateDir, "fullpage.tmpl"), []byte(`{{ .Content }}`), 0644)
+	os.WriteFile(filepath.Join(templateDir, "archive.tmpl"), []byte(`{{ .Title }}:{{ range .Files }} {{ .Name }}{{ end }}`), 0644)
+
+	config.UglyURLs = false
+	config.Archives = ArchiveConfig{Sections: []string{"post"}, Path: "/archive/"}
+
+	post := func(name string, date string) FileInfo {
+		parsed, _ := time.Parse(defaultDateFormat, date)
+		return FileInfo{Name: name, ContentType: "post", OutputPath: "/post/" + name + "/", Date: parsed,
+			MetaData: map[string]interface{}{"publish_date": date}}
+	}
+	dirsMap := map[string]DirectoryInfo{
+		"content/post": {Files: []FileInfo{
+			post("first", "2024-01-10"),
+			post("second", "2024-03-02"),
+			{Name: "undated", ContentType: "post", OutputPath: "/post/undated/"},
+		}},
+	}
+
+	b := Builder{rootPath: root, templateDir: templateDir, outputDir: filepath.Join(root, "web")}
+	if err := b.initTemplates(); err != nil {
+		t.Fatalf("initTemplates returned an error: %v", err)
+	}
+	if err := b.buildArchives(dirsMap); err != nil {
+		t.Fatalf("buildArchives returned an error: %v", err)
+	}
+
+	pages := map[string]string{
+		"post/2024/index.html":    "2024: second first",
+		"post/2024/01/index.html": "January 2024: first",
+		"post/2024/03/index.html": "March 2024: second",
+		"archive/index.html":      "Archive: second first",
+	}
+	for page, want := range pages {
+		content, err := os.ReadFile(filepath.Join(root, "web", filepath.FromSlash(page)))
+		if err != nil {
+			t.Errorf("Archive page %s not written: %v", page, err)
+			continue
+		}
+		if got := strings.TrimSpace(string(content)); got != want {
+			t.Errorf("Archive page %s mismatch. Got: %q, Want: %q", page, got, want)
+		}
+	}
+}
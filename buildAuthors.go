@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"path"
+)
+
+// AuthorsConfig controls the pages written for each author
+type AuthorsConfig struct {
+	// Path is the permalink each author's page is written under,
+	// e.g. /authors/ron/ - defaults to /authors/
+	// Leave it empty for no author pages or feeds
+	Path string `yaml:"path"`
+}
+
+// Author is the profile of an author, used in templates as .Authors
+// Profiles come from data/authors.yml (or data/authors/<slug>.yml) and from
+// content/authors/<slug>.md, with the body of the page as the bio.
+// Authors without a profile only have a name.
+type Author struct {
+	Slug   string            // The slug of the author, e.g. ron
+	Name   string            // The name of the author
+	Bio    template.HTML     // The bio of the author, rendered from markdown
+	Avatar string            // The URL of the author's picture
+	Social map[string]string // Links to the author's profiles, keyed by network (e.g. github)
+	URL    string            // The permalink of the author's page
+	Pages  Pages             // The pages by the author in the language, newest first
+	bio    string            // The raw bio, rendered once the content is rendered
+	page   *FileInfo         // The content page the profile came from
+}
+
+// The section of the content directory that holds the author profiles
+const authorsSection = "authors"
+
+// The template used for the author pages, list.tmpl is used when a site doesn't have one
+const authorTemplate = "author.tmpl"
+
+// **********  Private Author Methods  **********
+
+// Load the author profiles and link each page to the profiles of its authors,
+// matched by slug or name. Each language has its own copy of a profile that
+// lists the pages in the language.
+func (b *Builder) linkAuthors(dirsMap map[string]DirectoryInfo, data map[string]interface{}) error {
+	profiles, err := b.loadAuthors(dirsMap, data)
+	if err != nil {
+		return err
+	}
+
+	b.authors = make(map[string]map[string]*Author)
+	for dirKey, dirInfo := range dirsMap {
+		for i, file := range dirInfo.Files {
+			langProfiles, exists := profiles[file.Language]
+			if !exists {
+				langProfiles = profiles[config.DefaultLanguage]
+			}
+
+			var authors []*Author
+			for _, name := range metaStrings(file.MetaData, "author") {
+				profile, exists := langProfiles[slugify(name)]
+				if !exists {
+					profile = Author{Slug: slugify(name), Name: name}
+				}
+				if profile.Slug == "" {
+					continue
+				}
+
+				if b.authors[file.Language] == nil {
+					b.authors[file.Language] = make(map[string]*Author)
+				}
+				author, exists := b.authors[file.Language][profile.Slug]
+				if !exists {
+					if config.Authors.Path != "" {
+						profile.URL = listPermalink(authorDir(file.Language, profile.Slug))
+					}
+					author = &profile
+					b.authors[file.Language][profile.Slug] = author
+				}
+				authors = append(authors, author)
+			}
+			dirInfo.Files[i].Authors = authors
+		}
+		dirsMap[dirKey] = dirInfo
+	}
+	return nil
+}
+
+// Returns the author profiles of each language keyed by slug and by their
+// slugified name, from the data files and the pages in content/authors.
+// The profile pages are taken out of the directory map, other than an index
+// page listing the authors.
+func (b *Builder) loadAuthors(dirsMap map[string]DirectoryInfo, data map[string]interface{}) (map[string]map[string]Author, error) {
+	dataProfiles := make(map[string]Author)
+	errorPath := path.Join(dataDir, authorsSection)
+
+	// data/authors.yml holds a map of profiles keyed by slug, or a list
+	switch value := data[authorsSection].(type) {
+	case nil:
+	case map[string]interface{}:
+		for _, slug := range sortedKeys(value) {
+			fields, isMap := value[slug].(map[string]interface{})
+			if !isMap {
+				if err := b.addError(errorPath, "", fmt.Errorf("author %s should be a map of fields", slug)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			profile := authorProfile(fields, slug)
+			dataProfiles[profile.Slug] = profile
+		}
+	case []interface{}:
+		for _, item := range value {
+			if fields, isMap := item.(map[string]interface{}); isMap {
+				if profile := authorProfile(fields, ""); profile.Slug != "" {
+					dataProfiles[profile.Slug] = profile
+				}
+			}
+		}
+	default:
+		if err := b.addError(errorPath, "", fmt.Errorf("authors should be a map or a list of authors")); err != nil {
+			return nil, err
+		}
+	}
+
+	// The pages in content/authors are named by slug, with the bio as the body
+	pageProfiles := make(map[string]map[string]Author)
+	for _, file := range sortedFiles(dirsMap) {
+		if file.ContentType != authorsSection || file.Name == "index" {
+			continue
+		}
+		page := file
+		profile := authorProfile(file.MetaData, file.Name)
+		profile.page = &page
+		if pageProfiles[file.Language] == nil {
+			pageProfiles[file.Language] = make(map[string]Author)
+		}
+		pageProfiles[file.Language][profile.Slug] = profile
+	}
+	for dirKey, dirInfo := range dirsMap {
+		var files []FileInfo
+		for _, file := range dirInfo.Files {
+			if file.ContentType != authorsSection || file.Name == "index" {
+				files = append(files, file)
+			}
+		}
+		dirInfo.Files = files
+		dirInfo.NumFiles = len(files)
+		dirsMap[dirKey] = dirInfo
+	}
+
+	// Each language uses its own profile pages, then the default language's
+	// for authors without one, then the data files
+	profiles := make(map[string]map[string]Author)
+	for _, lang := range languageCodes() {
+		langProfiles := make(map[string]Author)
+		for _, source := range []map[string]Author{dataProfiles, pageProfiles[config.DefaultLanguage], pageProfiles[lang]} {
+			for slug, profile := range source {
+				langProfiles[slug] = profile
+			}
+		}
+
+		// Pages can name their authors by name as well as by slug
+		for _, slug := range sortedKeys(langProfiles) {
+			name := slugify(langProfiles[slug].Name)
+			if _, exists := langProfiles[name]; !exists && name != "" {
+				langProfiles[name] = langProfiles[slug]
+			}
+		}
+		profiles[lang] = langProfiles
+	}
+
+	return profiles, nil
+}
+
+// Render the bio of each author and list their pages, once the content is rendered
+func (b *Builder) renderAuthors(dirsMap map[string]DirectoryInfo) error {
+	for _, file := range sortedFiles(dirsMap) {
+		if file.Name == "index" {
+			continue
+		}
+		for _, author := range file.Authors {
+			author.Pages = append(author.Pages, file)
+		}
+	}
+
+	for _, authors := range b.authors {
+		for _, author := range authors {
+			author.Pages = author.Pages.ByDate()
+
+			var bio string
+			var err error
+			if author.page != nil {
+				bio, err = b.processMarkdown(*author.page)
+			} else if author.bio != "" {
+				var buf bytes.Buffer
+				err = b.markdown(FileInfo{}).Convert([]byte(author.bio), &buf)
+				bio = buf.String()
+			}
+			if err != nil {
+				if err := b.addError(path.Join(authorsSection, author.Slug), "", err); err != nil {
+					return err
+				}
+				continue
+			}
+			author.Bio = template.HTML(bio)
+		}
+	}
+	return nil
+}
+
+// Write the page and feed of each author, e.g. /authors/ron/ and /authors/ron/index.xml
+func (b *Builder) buildAuthorPages() error {
+	if config.Authors.Path == "" {
+		return nil
+	}
+
+	for _, lang := range languageCodes() {
+		authors := b.authors[lang]
+		for _, slug := range sortedKeys(authors) {
+			author := authors[slug]
+			if err := b.writeAuthorPage(lang, author); err != nil {
+				return err
+			}
+			if config.Feeds.Enabled {
+				title := b.sites[lang].Name + " - " + author.Name
+				if err := b.writeFeed(author.URL, title, lang, author.Pages); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Render the page of an author, listing their pages
+// The template gets the profile as .Author and the pages as .Files
+func (b *Builder) writeAuthorPage(lang string, author *Author) error {
+	metaData := map[string]interface{}{"title": author.Name, "image": author.Avatar}
+	if description := plainText(string(author.Bio)); description != "" {
+		metaData["description"] = truncateText(description, seoDescriptionLength)
+	}
+
+	pageData := PageData{
+		SiteName: config.Sitename,
+		Logo:     logo50,
+		Title:    author.Name,
+		Content:  author.Bio,
+		Metadata: metaData,
+		Page: FileInfo{
+			Path:       authorDir(lang, author.Slug),
+			OutputPath: author.URL,
+			Language:   lang,
+			MetaData:   metaData,
+		},
+		Files:  author.Pages,
+		Author: author,
+		Site:   b.sites[lang],
+	}
+	return b.writePage(authorTemplate, pageData)
+}
+
+// Returns the directory of the page of an author, e.g. authors/ron
+func authorDir(lang string, slug string) string {
+	return path.Join(languageDir(lang), config.Authors.Path, slug)
+}
+
+// Build a profile from the fields of a data record or front matter
+// The slug comes from the fields, the given slug or the name, in that order.
+func authorProfile(fields map[string]interface{}, slug string) Author {
+	profile := Author{
+		Slug:   slugify(metaString(fields, "slug")),
+		Name:   metaString(fields, "name"),
+		Avatar: metaString(fields, "avatar"),
+		Social: make(map[string]string),
+		bio:    metaString(fields, "bio"),
+	}
+	if profile.Name == "" {
+		profile.Name = metaString(fields, "title")
+	}
+	if profile.Slug == "" {
+		profile.Slug = slugify(slug)
+	}
+	if profile.Slug == "" {
+		profile.Slug = slugify(profile.Name)
+	}
+	if profile.Name == "" {
+		profile.Name = slug
+	}
+
+	switch social := fields["social"].(type) {
+	case map[string]interface{}:
+		for network, link := range social {
+			profile.Social[network] = fmt.Sprint(link)
+		}
+	case map[interface{}]interface{}:
+		for network, link := range social {
+			profile.Social[fmt.Sprint(network)] = fmt.Sprint(link)
+		}
+	}
+	return profile
+}
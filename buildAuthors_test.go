@@ -0,0 +1,158 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuilder_LinkAuthors(t *testing.T) {
+	restoreConfig(t)
+	config.DefaultLanguage = "en"
+	config.UglyURLs = false
+	config.Authors = AuthorsConfig{Path: "/authors/"}
+
+	data := map[string]interface{}{
+		"authors": map[string]interface{}{
+			"ron": map[string]interface{}{
+				"name":   "Ron Northcutt",
+				"bio":    "Builds **static sites**.",
+				"social": map[string]interface{}{"github": "https://github.com/rlnorthcutt"},
+			},
+		},
+	}
+	dirsMap := map[string]DirectoryInfo{
+		"content/authors": {NumFiles: 1, Files: []FileInfo{
+			{Name: "ann", Path: "authors/ann.md", ContentType: "authors", Language: "en",
+				MetaData: map[string]interface{}{"name": "Ann Lee", "avatar": "/ann.png"}, source: "Ann writes *docs*."},
+		}},
+		"content/post": {NumFiles: 3, Files: []FileInfo{
+			{Name: "joint", Path: "post/joint.md", OutputPath: "/post/joint/", Language: "en",
+				MetaData: map[string]interface{}{"author": []interface{}{"ann", "Ron Northcutt"}}},
+			{Name: "solo", Path: "post/solo.md", OutputPath: "/post/solo/", Language: "en",
+				MetaData: map[string]interface{}{"author": "Guest Writer"}},
+			{Name: "anonymous", Path: "post/anonymous.md", OutputPath: "/post/anonymous/", Language: "en",
+				MetaData: map[string]interface{}{}},
+		}},
+	}
+
+	b := Builder{}
+	if err := b.linkAuthors(dirsMap, data); err != nil {
+		t.Fatalf("linkAuthors returned an error: %v", err)
+	}
+	if files := dirsMap["content/authors"].Files; len(files) != 0 {
+		t.Errorf("Expected the profile pages to be taken out of the content. Got: %v", files)
+	}
+
+	posts := dirsMap["content/post"].Files
+	joint := posts[0].Authors
+	if len(joint) != 2 || joint[0].Name != "Ann Lee" || joint[1].Slug != "ron" {
+		t.Fatalf("Expected Ann Lee and ron. Got: %+v", joint)
+	}
+	if joint[1].URL != "/authors/ron/" || joint[1].Social["github"] != "https://github.com/rlnorthcutt" {
+		t.Errorf("Profile mismatch. Got: %+v", joint[1])
+	}
+	if guest := posts[1].Authors; len(guest) != 1 || guest[0].Slug != "guest-writer" || guest[0].Name != "Guest Writer" {
+		t.Errorf("Expected a profile with just a name for an unknown author. Got: %+v", guest)
+	}
+	if len(posts[2].Authors) != 0 {
+		t.Errorf("Expected no authors. Got: %+v", posts[2].Authors)
+	}
+
+	if err := b.renderAuthors(dirsMap); err != nil {
+		t.Fatalf("renderAuthors returned an error: %v", err)
+	}
+	ron := b.authors["en"]["ron"]
+	if !strings.Contains(string(ron.Bio), "<strong>static sites</strong>") {
+		t.Errorf("Expected the bio rendered from markdown. Got: %s", ron.Bio)
+	}
+	if ann := b.authors["en"]["ann"]; !strings.Contains(string(ann.Bio), "<em>docs</em>") || len(ann.Pages) != 1 {
+		t.Errorf("Expected the bio from the page and one page. Got: %s %v", ann.Bio, ann.Pages)
+	}
+}
+
+func TestBuilder_BuildAuthorPages(t *testing.T) {
+	restoreConfig(t)
+	root := t.TempDir()
+	templateDir := filepath.Join(root, "template")
+	os.MkdirAll(templateDir, 0755)
+	os.WriteFile(filepath.Join(templateDir, "fullpage.tmpl"), []byte(`{{ .Content }}`), 0644)
+	os.WriteFile(filepath.Join(templateDir, "author.tmpl"), []byte(`{{ .Author.Name }}:{{ range .Files }} {{ .Name }}{{ end }}`), 0644)
+
+	config.DefaultLanguage = "en"
+	config.UglyURLs = false
+	config.Authors = AuthorsConfig{Path: "/authors/"}
+	config.Feeds = FeedConfig{Enabled: true}
+
+	author := &Author{Slug: "ann", Name: "Ann Lee", URL: "/authors/ann/", Pages: Pages{
+		{Name: "second", OutputPath: "/post/second/"},
+		{Name: "first", OutputPath: "/post/first/"},
+	}}
+	b := Builder{rootPath: root, templateDir: templateDir, outputDir: filepath.Join(root, "web")}
+	b.authors = map[string]map[string]*Author{"en": {"ann": author}}
+	if err := b.initTemplates(); err != nil {
+		t.Fatalf("initTemplates returned an error: %v", err)
+	}
+	if err := b.buildAuthorPages(); err != nil {
+		t.Fatalf("buildAuthorPages returned an error: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(root, "web", "authors", "ann", "index.html"))
+	if err != nil {
+		t.Fatalf("Author page not written: %v", err)
+	}
+	if got, want := strings.TrimSpace(string(content)), "Ann Lee: second first"; got != want {
+		t.Errorf("Author page mismatch. Got: %q, Want: %q", got, want)
+	}
+	feed, err := os.ReadFile(filepath.Join(root, "web", "authors", "ann", "index.xml"))
+	if err != nil {
+		t.Fatalf("Author feed not written: %v", err)
+	}
+	if strings.Count(string(feed), "<item>") != 2 {
+		t.Errorf("Expected two items in the author feed. Got: %s", feed)
+	}
+}
+
+func TestBuilder_LinkAuthorsLanguages(t *testing.T) {
+	restoreConfig(t)
+	config.DefaultLanguage = "en"
+	config.Languages = map[string]LanguageConfig{"en": {}, "es": {}, "fr": {}}
+	config.Authors = AuthorsConfig{Path: "/authors/"}
+
+	profile := func(lang string, slug string, name string) FileInfo {
+		return FileInfo{Name: slug, Path: "authors/" + slug + "." + lang + ".md", ContentType: "authors", Language: lang,
+			MetaData: map[string]interface{}{"name": name}}
+	}
+	post := func(lang string) FileInfo {
+		return FileInfo{Name: "hello", Path: "post/hello." + lang + ".md", Language: lang,
+			MetaData: map[string]interface{}{"author": []interface{}{"ann", "ron"}}}
+	}
+	dirsMap := map[string]DirectoryInfo{
+		"content/authors": {NumFiles: 3, Files: []FileInfo{
+			profile("en", "ann", "Ann Lee"), profile("es", "ann", "Ana Lee"), profile("es", "ron", "Ramón"),
+		}},
+		"content/post": {NumFiles: 3, Files: []FileInfo{post("en"), post("es"), post("fr")}},
+	}
+
+	b := Builder{}
+	if err := b.linkAuthors(dirsMap, nil); err != nil {
+		t.Fatalf("linkAuthors returned an error: %v", err)
+	}
+
+	// Each language uses its own profile, then the default language's
+	want := map[string][]string{
+		"en": {"Ann Lee", "ron"},
+		"es": {"Ana Lee", "Ramón"},
+		"fr": {"Ann Lee", "ron"},
+	}
+	for _, file := range dirsMap["content/post"].Files {
+		var names []string
+		for _, author := range file.Authors {
+			names = append(names, author.Name)
+		}
+		if strings.Join(names, ", ") != strings.Join(want[file.Language], ", ") {
+			t.Errorf("Authors in %s mismatch. Got: %v, Want: %v", file.Language, names, want[file.Language])
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// **********  Private Bundle Methods  **********
+
+// Find the page bundles and the resources in the content directory.
+// A bundle is a directory below a section whose only page is an index page
+// (and its translations), e.g. content/post/trip/index.md. Its index page
+// is a page named after the directory, like content/post/trip.md would be.
+// Files that aren't pages and sit next to an index page, or anywhere in a
+// bundle, are resources copied beside the output of the index page.
+func (b *Builder) findBundles(contentPath string) error {
+	b.bundles = make(map[string]bool)
+	b.resources = make(map[string][]string)
+
+	// Collect the names of the pages in each directory
+	pages := make(map[string][]string)
+	var resources []string
+	err := filepath.Walk(contentPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(b.contentDir, path)
+		if err != nil {
+			return err
+		}
+		if !isPageFile(relPath) {
+			resources = append(resources, relPath)
+			return nil
+		}
+		_, _, _, fileName := contentLanguage(filepath.Dir(relPath), strings.TrimSuffix(filepath.Base(relPath), filepath.Ext(relPath)))
+		pages[filepath.Dir(relPath)] = append(pages[filepath.Dir(relPath)], fileName)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	hasIndex := make(map[string]bool)
+	for dir, names := range pages {
+		onlyIndex := true
+		for _, name := range names {
+			if name == "index" {
+				hasIndex[dir] = true
+			} else {
+				onlyIndex = false
+			}
+		}
+		if onlyIndex && isBundleDir(dir, pages) {
+			b.bundles[dir] = true
+		}
+	}
+
+	// Each resource belongs to the bundle it is in, or the index page next to it
+	for _, resource := range resources {
+		if owner := b.resourceOwner(resource); hasIndex[owner] {
+			b.resources[owner] = append(b.resources[owner], resource)
+		}
+	}
+	return nil
+}
+
+// Returns the bundle a directory is in, or an empty string when it isn't in one
+func (b *Builder) bundleDir(dir string) string {
+	for dir != "." && dir != "" && dir != string(filepath.Separator) {
+		if b.bundles[dir] {
+			return dir
+		}
+		dir = filepath.Dir(dir)
+	}
+	return ""
+}
+
+// Check if a resource is copied with an index page, rather than processed as a page
+func (b *Builder) isResource(relPath string) bool {
+	if isPageFile(relPath) {
+		return false
+	}
+	for _, resource := range b.resources[b.resourceOwner(relPath)] {
+		if resource == relPath {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the directory whose index page a resource is copied with
+func (b *Builder) resourceOwner(relPath string) string {
+	dir := filepath.Dir(relPath)
+	if owner := b.bundleDir(dir); owner != "" {
+		return owner
+	}
+	return dir
+}
+
+// Copy the resources of an index page beside its output, keeping their path
+// relative to the page, e.g. post/trip/cover.png to web/post/trip/cover.png
+func (b *Builder) copyResources(file FileInfo) error {
+	if !strings.HasPrefix(filepath.Base(file.Path), "index.") {
+		return nil
+	}
+	dir := filepath.Dir(file.Path)
+	outputDir := filepath.Dir(b.outputFilePath(file.OutputPath))
+	for _, resource := range b.resources[dir] {
+		relPath, err := filepath.Rel(dir, resource)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(filepath.Join(b.contentDir, resource))
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(outputDir, relPath)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check if a bundle directory is below a section and has no pages in its subdirectories
+func isBundleDir(dir string, pages map[string][]string) bool {
+	_, sectionDir, _, _ := contentLanguage(dir, "index")
+	if !strings.Contains(filepath.ToSlash(sectionDir), "/") {
+		return false
+	}
+	for other := range pages {
+		if strings.HasPrefix(other, dir+string(filepath.Separator)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the directory and name of the page of a bundle, from the directory
+// of the bundle, e.g. post and trip for post/trip
+func bundlePage(dir string) (string, string) {
+	parent := filepath.Dir(dir)
+	if parent == "." {
+		parent = ""
+	}
+	return parent, filepath.Base(dir)
+}
+
+// Returns the permalink of the page of a bundle. Bundles are always written
+// to index.html in their own directory, so their resources sit beside them.
+func bundlePermalink(permalink string, metaData map[string]interface{}) string {
+	if metaString(metaData, "url") != "" || strings.HasSuffix(permalink, "/") || path.Base(permalink) == "index.html" {
+		return permalink
+	}
+	return strings.TrimSuffix(permalink, path.Ext(permalink)) + "/index.html"
+}
+
+// Check if a content file is a page, rather than a resource such as an image
+func isPageFile(relPath string) bool {
+	extension := strings.ToLower(filepath.Ext(relPath))
+	return extension == ".md" || extension == ".html"
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// Write the files to a content directory and find the bundles in it
+func bundleBuilder(t *testing.T, files []string) Builder {
+	t.Helper()
+	root := t.TempDir()
+	b := Builder{contentDir: filepath.Join(root, "content"), outputDir: filepath.Join(root, "web")}
+	for _, name := range files {
+		path := filepath.Join(b.contentDir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(name), 0644)
+	}
+	if err := b.findBundles(b.contentDir); err != nil {
+		t.Fatalf("findBundles returned an error: %v", err)
+	}
+	return b
+}
+
+func TestBuilder_FindBundles(t *testing.T) {
+	restoreConfig(t)
+
+	b := bundleBuilder(t, []string{
+		"index.md",
+		"post/index.md",
+		"post/banner.png",
+		"post/other.md",
+		"post/trip/index.md",
+		"post/trip/cover.png",
+		"post/trip/img/map.png",
+		"post/series/index.md",
+		"post/series/part-1.md",
+		"post/nested/index.md",
+		"post/nested/child/index.md",
+		"post/nested/child/photo.jpg",
+		"notes/photo.jpg",
+	})
+
+	// Only a directory below a section, without other pages, is a bundle
+	var bundles []string
+	for dir := range b.bundles {
+		bundles = append(bundles, filepath.ToSlash(dir))
+	}
+	sort.Strings(bundles)
+	if want := []string{"post/nested/child", "post/trip"}; !reflect.DeepEqual(bundles, want) {
+		t.Errorf("Bundles mismatch. Got: %v, Want: %v", bundles, want)
+	}
+
+	tests := []struct {
+		relPath string
+		want    bool
+	}{
+		{"post/trip/cover.png", true},
+		{"post/trip/img/map.png", true},
+		{"post/banner.png", true},
+		{"post/nested/child/photo.jpg", true},
+		{"post/trip/index.md", false},
+		// A file without an index page next to it isn't a resource
+		{"notes/photo.jpg", false},
+	}
+	for _, tt := range tests {
+		if got := b.isResource(filepath.FromSlash(tt.relPath)); got != tt.want {
+			t.Errorf("isResource(%s) = %v, want %v", tt.relPath, got, tt.want)
+		}
+	}
+}
+
+func TestBuilder_CopyResources(t *testing.T) {
+	restoreConfig(t)
+
+	b := bundleBuilder(t, []string{
+		"post/trip/index.md",
+		"post/trip/cover.png",
+		"post/trip/img/map.png",
+	})
+	file := FileInfo{Path: filepath.FromSlash("post/trip/index.md"), OutputPath: "/post/trip/index.html"}
+	if err := b.copyResources(file); err != nil {
+		t.Fatalf("copyResources returned an error: %v", err)
+	}
+
+	// The resources keep their path relative to the page
+	for _, name := range []string{"post/trip/cover.png", "post/trip/img/map.png"} {
+		content, err := os.ReadFile(filepath.Join(b.outputDir, filepath.FromSlash(name)))
+		if err != nil || string(content) != name {
+			t.Errorf("Resource %s not copied. Got: %q, %v", name, content, err)
+		}
+	}
+}
+
+func TestBundlePermalink(t *testing.T) {
+	tests := []struct {
+		permalink string
+		metaData  map[string]interface{}
+		want      string
+	}{
+		{"/post/trip/", nil, "/post/trip/"},
+		{"/post/trip.html", nil, "/post/trip/index.html"},
+		{"/post/trip/index.html", nil, "/post/trip/index.html"},
+		// An explicit URL is kept as it is
+		{"/trip.html", map[string]interface{}{"url": "/trip.html"}, "/trip.html"},
+	}
+	for _, tt := range tests {
+		if got := bundlePermalink(tt.permalink, tt.metaData); got != tt.want {
+			t.Errorf("bundlePermalink(%q) = %q, want %q", tt.permalink, got, tt.want)
+		}
+	}
+
+	if dir, name := bundlePage(filepath.FromSlash("post/trip")); dir != "post" || name != "trip" {
+		t.Errorf("bundlePage(post/trip) = %q, %q, want post, trip", dir, name)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The directory (relative to the root) that holds the data files
+const dataDir = "data"
+
+// **********  Private Data Methods  **********
+
+// Load every data file into a nested tree, keyed by directory and file name.
+// data/team.yml is available in templates as .Site.Data.team, and
+// data/projects/web.json as .Site.Data.projects.web
+func (b *Builder) loadData() (map[string]interface{}, error) {
+	data := make(map[string]interface{})
+	root := filepath.Join(b.rootPath, dataDir)
+	if !filesystem.Exists(root) {
+		return data, nil
+	}
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		extension := strings.ToLower(filepath.Ext(relPath))
+		if !isDataFile(extension) {
+			return nil
+		}
+
+		errorPath := filepath.ToSlash(filepath.Join(dataDir, relPath))
+		value, err := b.loadDataFile(path, extension)
+		if err != nil {
+			return b.recordError(dataFileError(errorPath, err))
+		}
+
+		// Place the value in the tree at its directory and file name
+		keys := strings.Split(filepath.ToSlash(strings.TrimSuffix(relPath, filepath.Ext(relPath))), "/")
+		if err := setDataValue(data, keys, value); err != nil {
+			return b.addError(errorPath, "", err)
+		}
+		return nil
+	})
+
+	return data, err
+}
+
+// Read and parse a single data file
+func (b *Builder) loadDataFile(path string, extension string) (interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch extension {
+	case ".yml", ".yaml":
+		var value interface{}
+		if err := yaml.Unmarshal(content, &value); err != nil {
+			return nil, err
+		}
+		return stringKeys(value), nil
+	case ".json":
+		// Numbers are decoded the way JSON front matter is, so an id of
+		// 1234567 stays a whole number rather than 1.234567e+06
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return nil, jsonError(content, err)
+		}
+		if _, err := decoder.Token(); err != io.EOF {
+			return nil, fmt.Errorf("line %d: invalid content after the top-level value", bytes.Count(content[:decoder.InputOffset()], []byte("\n"))+1)
+		}
+		return jsonNumbers(value), nil
+	case ".toml":
+		return filesystem.ParseToml(string(content))
+	case ".csv":
+		return parseCSV(content)
+	}
+	return nil, fmt.Errorf("unsupported data file %s", extension)
+}
+
+// Check if a file extension is a supported data file
+func isDataFile(extension string) bool {
+	switch extension {
+	case ".yml", ".yaml", ".json", ".toml", ".csv":
+		return true
+	}
+	return false
+}
+
+// Set a value in the data tree, creating the maps for the directories
+func setDataValue(data map[string]interface{}, keys []string, value interface{}) error {
+	for _, key := range keys[:len(keys)-1] {
+		next, exists := data[key]
+		if !exists {
+			next = make(map[string]interface{})
+			data[key] = next
+		}
+		nextMap, isMap := next.(map[string]interface{})
+		if !isMap {
+			return fmt.Errorf("data key %s is used by a file and a directory", key)
+		}
+		data = nextMap
+	}
+
+	last := keys[len(keys)-1]
+	if _, exists := data[last]; exists {
+		return fmt.Errorf("data key %s is used by more than one file", strings.Join(keys, "."))
+	}
+	data[last] = value
+	return nil
+}
+
+// Parse a CSV file into a list of rows, keyed by the names in the header row
+func parseCSV(content []byte) ([]interface{}, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []interface{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []interface{}{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(header))
+		for i, name := range header {
+			row[strings.TrimSpace(name)] = record[i]
+		}
+		rows = append(rows, row)
+	}
+}
+
+// Add the line number to a JSON error, which only has the byte offset
+func jsonError(content []byte, err error) error {
+	var offset int64
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxError):
+		offset = syntaxError.Offset
+	case errors.As(err, &typeError):
+		offset = typeError.Offset
+	default:
+		return err
+	}
+	line := bytes.Count(content[:min(int(offset), len(content))], []byte("\n")) + 1
+	return fmt.Errorf("line %d: %w", line, err)
+}
+
+// Build the error for a data file, with the line number from the parser error
+func dataFileError(file string, err error) BuildError {
+	buildError := BuildError{File: file, Err: err}
+
+	var csvError *csv.ParseError
+	if errors.As(err, &csvError) {
+		buildError.Line = csvError.Line
+	} else if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+		// The YAML, TOML and JSON errors all name the line as "line N"
+		buildError.Line, _ = strconv.Atoi(match[1])
+	}
+	return buildError
+}
+
+// Convert the maps from the YAML parser to use string keys, the same as the
+// JSON and TOML parsers
+func stringKeys(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			converted[fmt.Sprint(key)] = stringKeys(item)
+		}
+		return converted
+	case []interface{}:
+		for i, item := range value {
+			value[i] = stringKeys(item)
+		}
+		return value
+	}
+	return value
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuilder_LoadData(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"data/team.yml":          "members:\n  - name: Ann\n  - name: Bob\n",
+		"data/projects/web.json": `{"name": "Web", "id": 1234567, "rating": 4.5}`,
+		"data/products.csv":      "name,price\nWidget,3\nGadget,5\n",
+		"data/site.toml":         "title = \"Repose\"\n",
+		"data/bad.json":          "{\n  \"name\": oops\n}",
+		"data/notes.txt":         "ignored",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	b := Builder{rootPath: root}
+	data, err := b.loadData()
+	if err != nil {
+		t.Fatalf("loadData returned an error: %v", err)
+	}
+
+	members := data["team"].(map[string]interface{})["members"].([]interface{})
+	if name := members[1].(map[string]interface{})["name"]; name != "Bob" {
+		t.Errorf("YAML data mismatch. Got: %v, Want: Bob", name)
+	}
+	if name := data["projects"].(map[string]interface{})["web"].(map[string]interface{})["name"]; name != "Web" {
+		t.Errorf("JSON data mismatch. Got: %v, Want: Web", name)
+	}
+	// JSON numbers are read the same way as YAML and TOML numbers
+	web := data["projects"].(map[string]interface{})["web"].(map[string]interface{})
+	if web["id"] != 1234567 || web["rating"] != 4.5 {
+		t.Errorf("JSON numbers mismatch. Got: %#v, %#v", web["id"], web["rating"])
+	}
+	products := data["products"].([]interface{})
+	if len(products) != 2 || products[1].(map[string]interface{})["price"] != "5" {
+		t.Errorf("CSV data mismatch. Got: %v", products)
+	}
+	if title := data["site"].(map[string]interface{})["title"]; title != "Repose" {
+		t.Errorf("TOML data mismatch. Got: %v, Want: Repose", title)
+	}
+	if _, exists := data["notes"]; exists {
+		t.Error("Files that aren't data files should be skipped")
+	}
+
+	// Parse errors are collected with the file and line
+	if len(b.errors) != 1 || b.errors[0].File != "data/bad.json" || b.errors[0].Line != 2 {
+		t.Errorf("Expected an error for data/bad.json on line 2. Got: %v", b.errors)
+	}
+}
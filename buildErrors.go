@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BuildError holds an error for a single content file or template
+type BuildError struct {
+	File     string // The content file (or directory for index pages) being built
+	Template string // The template being executed, if any
+	Line     int    // The line in the template, or in the content file when there is no template
+	Err      error  // The underlying error
+}
+
+// BuildErrors holds every error collected while building the site
+type BuildErrors []BuildError
+
+// Matches the template name and line in a template error message, e.g.
+// template: post/single.tmpl:12:5: executing "main" at <.Title>: ...
+var templateErrorPattern = regexp.MustCompile(`template: ([^:\s]+):(\d+)`)
+
+// **********  Public BuildError Methods  **********
+
+// Error returns the error with its file, template and line
+func (e BuildError) Error() string {
+	location := e.File
+	switch {
+	case e.Template != "" && e.Line > 0:
+		location += fmt.Sprintf(" (%s:%d)", e.Template, e.Line)
+	case e.Template != "":
+		location += fmt.Sprintf(" (%s)", e.Template)
+	case e.Line > 0:
+		location += fmt.Sprintf(":%d", e.Line)
+	}
+	return location + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e BuildError) Unwrap() error {
+	return e.Err
+}
+
+// Error returns a summary of every error, one per line
+func (e BuildErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, buildError := range e {
+		messages[i] = buildError.Error()
+	}
+	return fmt.Sprintf("%d build errors:\n%s", len(e), strings.Join(messages, "\n"))
+}
+
+// Print the errors grouped by the content file they belong to
+func (e BuildErrors) Print() {
+	groups := make(map[string][]BuildError)
+	for _, buildError := range e {
+		groups[buildError.File] = append(groups[buildError.File], buildError)
+	}
+
+	for _, file := range sortedKeys(groups) {
+		logger.Error("%s", file)
+		for _, buildError := range groups[file] {
+			location := ""
+			switch {
+			case buildError.Template != "" && buildError.Line > 0:
+				location = fmt.Sprintf("%s:%d: ", buildError.Template, buildError.Line)
+			case buildError.Template != "":
+				location = buildError.Template + ": "
+			case buildError.Line > 0:
+				location = fmt.Sprintf("line %d: ", buildError.Line)
+			}
+			fmt.Printf("        %s%s\n", location, strings.TrimSpace(buildError.Err.Error()))
+		}
+	}
+}
+
+// **********  Private Builder Error Methods  **********
+
+// Record an error for a content file and keep building.
+// Returns the error when the build should stop at the first error.
+func (b *Builder) addError(file string, templateName string, err error) error {
+	buildError := BuildError{File: file, Template: templateName, Err: err}
+
+	// Template errors carry the name and line of the template that failed,
+	// which may be a partial rather than the page template
+	if match := templateErrorPattern.FindStringSubmatch(err.Error()); match != nil {
+		buildError.Template = match[1]
+		buildError.Line, _ = strconv.Atoi(match[2])
+	} else if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+		// Front matter lines are counted from the opening delimiter
+		line, _ := strconv.Atoi(match[1])
+		buildError.Line = line + 1
+	}
+
+	return b.recordError(buildError)
+}
+
+// Record an error that already has its file and line.
+// Returns the error when the build should stop at the first error.
+func (b *Builder) recordError(buildError BuildError) error {
+	if b.failFast {
+		return buildError
+	}
+	b.errors = append(b.errors, buildError)
+	return nil
+}
+
+// Returns the collected errors, sorted by file, or nil if there were none
+func (b *Builder) collectedErrors() error {
+	if len(b.errors) == 0 {
+		return nil
+	}
+	sort.SliceStable(b.errors, func(i, j int) bool {
+		return b.errors[i].File < b.errors[j].File
+	})
+	return BuildErrors(b.errors)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuilder_AddError(t *testing.T) {
+	b := Builder{}
+
+	templateErr := errors.New(`template: partials/nav.tmpl:12:5: executing "partials/nav.tmpl" at <.Menu>: nil pointer`)
+	if err := b.addError("post/one.md", "default.tmpl", templateErr); err != nil {
+		t.Fatalf("addError returned an error without failFast: %v", err)
+	}
+	b.addError("about.md", "", errors.New("error parsing metadata: yaml: line 3: mapping values are not allowed"))
+
+	err := b.collectedErrors()
+	var buildErrors BuildErrors
+	if !errors.As(err, &buildErrors) || len(buildErrors) != 2 {
+		t.Fatalf("Collected errors mismatch. Got: %v", err)
+	}
+
+	// Errors are sorted by file
+	if buildErrors[0].File != "about.md" || buildErrors[0].Line != 4 {
+		t.Errorf("Front matter error mismatch. Got: %s:%d, Want: about.md:4", buildErrors[0].File, buildErrors[0].Line)
+	}
+	// The template in the error message wins over the page template
+	if buildErrors[1].Template != "partials/nav.tmpl" || buildErrors[1].Line != 12 {
+		t.Errorf("Template error mismatch. Got: %s:%d, Want: partials/nav.tmpl:12", buildErrors[1].Template, buildErrors[1].Line)
+	}
+
+	// With failFast the first error is returned instead of collected
+	b = Builder{failFast: true}
+	if err := b.addError("post/one.md", "", templateErr); err == nil {
+		t.Error("addError with failFast should return the error")
+	}
+	if len(b.errors) != 0 {
+		t.Errorf("addError with failFast should not collect errors. Got: %d", len(b.errors))
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FeedConfig controls the RSS feeds written for the site and each section
+type FeedConfig struct {
+	// Enabled writes a feed for each language and section - defaults to true
+	Enabled bool `yaml:"enabled"`
+	// Limit is the number of pages in each feed, newest first - defaults to 20
+	// Use 0 for every page
+	Limit int `yaml:"limit"`
+}
+
+// The file each feed is written to, in the directory of the pages it lists
+const feedFile = "index.xml"
+
+// The elements of an RSS 2.0 feed
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Description   string      `xml:"description"`
+	Language      string      `xml:"language,omitempty"`
+	LastBuildDate string      `xml:"lastBuildDate,omitempty"`
+	Self          rssAtomLink `xml:"atom:link"`
+	Items         []rssItem   `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+	Description string `xml:"description,omitempty"`
+}
+
+// **********  Private Feed Methods  **********
+
+// Write an RSS feed of the pages in each language, at /index.xml and
+// /es/index.xml, and of the pages in each section, e.g. /post/index.xml
+func (b *Builder) buildFeeds(dirsMap map[string]DirectoryInfo) error {
+	if !config.Feeds.Enabled {
+		return nil
+	}
+	logger.Info("Building feeds")
+
+	for _, language := range siteLanguages() {
+		var files []FileInfo
+		for _, file := range sortedFiles(dirsMap) {
+			if file.Language == language.Code {
+				files = append(files, file)
+			}
+		}
+		site := b.sites[language.Code]
+		if err := b.writeFeed(language.URL, site.Name, language.Code, files); err != nil {
+			return err
+		}
+	}
+
+	for _, dirInfo := range dirsMap {
+		// The pages at the root of each language are in the language feed
+		dir := filepath.ToSlash(dirInfo.Path)
+		if dirInfo.NumFiles == 0 || dir == "." || dir == "" || isLanguage(dir) {
+			continue
+		}
+		lang := dirLanguage(dir)
+		title := b.sites[lang].Name + " - " + dirInfo.Files[0].ContentType
+		if err := b.writeFeed(listPermalink(dir), title, lang, dirInfo.Files); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Write the feed for a list page with its pages, newest first.
+// Index pages and pages with `index: false` are left out.
+func (b *Builder) writeFeed(listURL string, title string, lang string, files []FileInfo) error {
+	var pages []FileInfo
+	for _, file := range files {
+		if index, exists := file.MetaData["index"].(bool); (exists && !index) || file.Name == "index" {
+			continue
+		}
+		pages = append(pages, file)
+	}
+	sort.SliceStable(pages, func(i, j int) bool {
+		if !pages[i].Date.Equal(pages[j].Date) {
+			return pages[i].Date.After(pages[j].Date)
+		}
+		return pages[i].OutputPath < pages[j].OutputPath
+	})
+	if config.Feeds.Limit > 0 && len(pages) > config.Feeds.Limit {
+		pages = pages[:config.Feeds.Limit]
+	}
+
+	// The feed sits in the directory of its list page
+	feedURL := path.Join(strings.TrimSuffix(listURL, "index.html"), feedFile)
+	feed := rssFeed{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:       title,
+			Link:        absoluteURL(listURL),
+			Description: "Recent content from " + title,
+			Language:    lang,
+			Self:        rssAtomLink{Href: absoluteURL(feedURL), Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	if len(pages) > 0 {
+		feed.Channel.LastBuildDate = pages[0].Date.Format(time.RFC1123Z)
+	}
+	for _, page := range pages {
+		pageTitle := metaString(page.MetaData, "title")
+		if pageTitle == "" {
+			pageTitle = page.Name
+		}
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       pageTitle,
+			Link:        absoluteURL(page.OutputPath),
+			GUID:        absoluteURL(page.OutputPath),
+			PubDate:     page.Date.Format(time.RFC1123Z),
+			Description: pageSummary(page, config.Search.SummaryLength),
+		})
+	}
+
+	output, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error writing feed %s: %w", feedURL, err)
+	}
+	return filesystem.Create(b.outputFilePath(feedURL), xml.Header+string(output))
+}
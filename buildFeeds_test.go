@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuilder_WriteFeed(t *testing.T) {
+	restoreConfig(t)
+	outputDir := t.TempDir()
+	config.UglyURLs = false
+	config.URL = "example.com"
+	config.Feeds = FeedConfig{Enabled: true, Limit: 2}
+
+	files := []FileInfo{
+		{Name: "index", OutputPath: "/post/", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "old", OutputPath: "/post/old/", Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			MetaData: map[string]interface{}{"title": "Old & gone"}},
+		{Name: "new", OutputPath: "/post/new/", Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			MetaData: map[string]interface{}{"title": "New", "description": "The newest post"}},
+		{Name: "hidden", OutputPath: "/post/hidden/", Date: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			MetaData: map[string]interface{}{"index": false}},
+		{Name: "older", OutputPath: "/post/older/", Date: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	b := Builder{outputDir: outputDir}
+	if err := b.writeFeed("/post/", "Site - post", "en", files); err != nil {
+		t.Fatalf("writeFeed returned an error: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "post", "index.xml"))
+	if err != nil {
+		t.Fatalf("Feed not written: %v", err)
+	}
+	feed := string(content)
+	for _, want := range []string{
+		`<atom:link href="https://example.com/post/index.xml" rel="self"`,
+		`<link>https://example.com/post/new/</link>`,
+		`<description>The newest post</description>`,
+		`<title>Old &amp; gone</title>`,
+		`<language>en</language>`,
+	} {
+		if !strings.Contains(feed, want) {
+			t.Errorf("Feed is missing %s. Got:\n%s", want, feed)
+		}
+	}
+	// Index pages, hidden pages and pages past the limit are left out
+	for _, unwanted := range []string{"/post/</link><guid", "hidden", "older"} {
+		if strings.Contains(feed, unwanted) {
+			t.Errorf("Feed should not contain %s. Got:\n%s", unwanted, feed)
+		}
+	}
+	if strings.Index(feed, "/post/new/") > strings.Index(feed, "/post/old/") {
+		t.Error("Feed items should be sorted newest first")
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The formats of front matter, found from the delimiter at the top of a content file
+const (
+	frontMatterYAML = "yaml" // Between --- lines
+	frontMatterTOML = "toml" // Between +++ lines
+	frontMatterJSON = "json" // A JSON object, starting with {
+)
+
+// Matches the first line of JSON front matter, without matching a shortcode
+var jsonFrontMatterPattern = regexp.MustCompile(`^\{\s*("|\}|$)`)
+
+// **********  Private Front Matter Methods  **********
+
+// Split the front matter from the body of a content file.
+// Returns the format, the front matter, the line it starts on and the body.
+// The format is empty when the file doesn't have front matter.
+func splitFrontMatter(source string) (string, string, int, string) {
+	lines := strings.SplitAfter(strings.TrimPrefix(source, "\ufeff"), "\n")
+
+	// Skip any blank lines before the opening delimiter
+	start := 0
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	if start == len(lines) {
+		return "", "", 0, source
+	}
+
+	first := strings.TrimSpace(lines[start])
+	switch {
+	case first == "---" || first == "+++":
+		format := frontMatterYAML
+		if first == "+++" {
+			format = frontMatterTOML
+		}
+		for end := start + 1; end < len(lines); end++ {
+			if strings.TrimSpace(lines[end]) == first {
+				return format, strings.Join(lines[start+1:end], ""), start + 2, strings.Join(lines[end+1:], "")
+			}
+		}
+	case jsonFrontMatterPattern.MatchString(first):
+		// The object ends where the JSON decoder stops reading
+		rest := strings.Join(lines[start:], "")
+		decoder := json.NewDecoder(strings.NewReader(rest))
+		var object json.RawMessage
+		if err := decoder.Decode(&object); err != nil {
+			return frontMatterJSON, rest, start + 1, ""
+		}
+		end := int(decoder.InputOffset())
+		body := strings.TrimPrefix(strings.TrimPrefix(strings.TrimLeft(rest[end:], " \t"), "\r"), "\n")
+		return frontMatterJSON, rest[:end], start + 1, body
+	}
+
+	return "", "", 0, source
+}
+
+// Parse front matter in the given format into a metadata map.
+// Numbers are returned as int when they are whole, the same as the YAML parser.
+func parseFrontMatter(format string, frontMatter string) (map[string]interface{}, error) {
+	metaData := make(map[string]interface{})
+	switch format {
+	case frontMatterYAML:
+		if err := yaml.Unmarshal([]byte(frontMatter), &metaData); err != nil {
+			return nil, err
+		}
+	case frontMatterTOML:
+		return filesystem.ParseToml(frontMatter)
+	case frontMatterJSON:
+		decoder := json.NewDecoder(strings.NewReader(frontMatter))
+		decoder.UseNumber()
+		if err := decoder.Decode(&metaData); err != nil {
+			return nil, jsonError([]byte(frontMatter), err)
+		}
+		return jsonNumbers(metaData).(map[string]interface{}), nil
+	}
+	return metaData, nil
+}
+
+// Convert the YAML front matter of a content file to the given format.
+// Used to write new content in the front matter format set in the config.
+// Files in other formats, or without front matter, are returned as they are.
+func convertFrontMatter(source string, format string) (string, error) {
+	current, frontMatter, _, body := splitFrontMatter(source)
+	if current != frontMatterYAML || format == "" || format == frontMatterYAML {
+		return source, nil
+	}
+
+	var items yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(frontMatter), &items); err != nil {
+		return "", fmt.Errorf("error parsing front matter: %w", err)
+	}
+
+	var output bytes.Buffer
+	switch format {
+	case frontMatterTOML:
+		output.WriteString("+++\n")
+		for _, item := range items {
+			fmt.Fprintf(&output, "%s = %s\n", item.Key, tomlValue(item.Value))
+		}
+		output.WriteString("+++\n")
+	case frontMatterJSON:
+		output.WriteString("{\n")
+		for i, item := range items {
+			key, _ := json.Marshal(fmt.Sprint(item.Key))
+			value, err := json.Marshal(jsonValue(item.Value))
+			if err != nil {
+				return "", err
+			}
+			separator := ","
+			if i == len(items)-1 {
+				separator = ""
+			}
+			fmt.Fprintf(&output, "  %s: %s%s\n", key, value, separator)
+		}
+		output.WriteString("}\n")
+	default:
+		return "", fmt.Errorf("unknown front matter format %q, use yaml, toml or json", format)
+	}
+
+	return output.String() + body, nil
+}
+
+// Returns a value from YAML as TOML. TOML has no null, so empty values are empty strings.
+func tomlValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return `""`
+	case string:
+		return strconv.Quote(value)
+	case bool, int, float64:
+		return fmt.Sprint(value)
+	case []interface{}:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = tomlValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case yaml.MapSlice:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = fmt.Sprintf("%s = %s", item.Key, tomlValue(item.Value))
+		}
+		return "{ " + strings.Join(items, ", ") + " }"
+	}
+	return strconv.Quote(fmt.Sprint(value))
+}
+
+// Returns a value from YAML that can be written as JSON, with empty values as empty strings
+func jsonValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]interface{}, len(value))
+		for i, item := range value {
+			items[i] = jsonValue(item)
+		}
+		return items
+	case yaml.MapSlice:
+		items := make(map[string]interface{}, len(value))
+		for _, item := range value {
+			items[fmt.Sprint(item.Key)] = jsonValue(item.Value)
+		}
+		return items
+	}
+	return value
+}
+
+// Convert the numbers from the JSON decoder to int when they are whole, or float64
+func jsonNumbers(value interface{}) interface{} {
+	switch value := value.(type) {
+	case json.Number:
+		if number, err := value.Int64(); err == nil {
+			return int(number)
+		}
+		number, _ := value.Float64()
+		return number
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = jsonNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = jsonNumbers(item)
+		}
+	}
+	return value
+}
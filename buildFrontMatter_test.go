@@ -0,0 +1,85 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		format      string
+		frontMatter string
+		line        int
+		body        string
+	}{
+		{"yaml", "---\ntitle: Hi\n---\nbody", frontMatterYAML, "title: Hi\n", 2, "body"},
+		{"toml", "\n+++\ntitle = \"Hi\"\n+++\nbody", frontMatterTOML, "title = \"Hi\"\n", 3, "body"},
+		{"json", "{\n  \"title\": \"Hi\"\n}\nbody", frontMatterJSON, "{\n  \"title\": \"Hi\"\n}", 1, "body"},
+		{"json on one line", `{"title": "Hi"} body`, frontMatterJSON, `{"title": "Hi"}`, 1, "body"},
+		{"shortcode", "{{< note >}}hi{{< /note >}}", "", "", 0, "{{< note >}}hi{{< /note >}}"},
+		{"no front matter", "# Hi", "", "", 0, "# Hi"},
+		{"unclosed", "+++\ntitle = \"Hi\"", "", "", 0, "+++\ntitle = \"Hi\""},
+	}
+	for _, tt := range tests {
+		format, frontMatter, line, body := splitFrontMatter(tt.source)
+		if format != tt.format || frontMatter != tt.frontMatter || line != tt.line || body != tt.body {
+			t.Errorf("%s: splitFrontMatter mismatch. Got: %q %q %d %q", tt.name, format, frontMatter, line, body)
+		}
+	}
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	want := map[string]interface{}{
+		"title":        "Hi",
+		"series_order": 2,
+		"rating":       4.5,
+		"tags":         []interface{}{"go", "toml"},
+	}
+
+	toml, err := parseFrontMatter(frontMatterTOML, "title = \"Hi\"\nseries_order = 2\nrating = 4.5\ntags = [\"go\", \"toml\"]\n")
+	if err != nil || !reflect.DeepEqual(toml, want) {
+		t.Errorf("TOML mismatch. Got: %v, %v", toml, err)
+	}
+	json, err := parseFrontMatter(frontMatterJSON, `{"title": "Hi", "series_order": 2, "rating": 4.5, "tags": ["go", "toml"]}`)
+	if err != nil || !reflect.DeepEqual(json, want) {
+		t.Errorf("JSON mismatch. Got: %v, %v", json, err)
+	}
+	if _, err := parseFrontMatter(frontMatterJSON, "{\n  \"title\": \"Hi\",\n}"); err == nil {
+		t.Errorf("Expected an error for invalid JSON")
+	}
+}
+
+func TestConvertFrontMatter(t *testing.T) {
+	source := "---\ntitle: Hi\ntags: []\nindex: true\npublish_date: \n---\n# Hi\n"
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{frontMatterYAML, source},
+		{frontMatterTOML, "+++\ntitle = \"Hi\"\ntags = []\nindex = true\npublish_date = \"\"\n+++\n# Hi\n"},
+		{frontMatterJSON, "{\n  \"title\": \"Hi\",\n  \"tags\": [],\n  \"index\": true,\n  \"publish_date\": \"\"\n}\n# Hi\n"},
+	}
+	for _, tt := range tests {
+		got, err := convertFrontMatter(source, tt.format)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: convertFrontMatter mismatch. Got: %q, %v, Want: %q", tt.format, got, err, tt.want)
+		}
+	}
+	if _, err := convertFrontMatter(source, "xml"); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
+
+func TestBuilder_ExtractMetadata(t *testing.T) {
+	b := Builder{}
+	metaData, err := b.extractMetadata("+++\ntitle = \"Hi\"\n[menu.main]\nweight = 5\n+++\nbody")
+	if err != nil {
+		t.Fatalf("extractMetadata returned an error: %v", err)
+	}
+	entries, err := menuEntries(metaData["menu"])
+	if err != nil || len(entries) != 1 || entries[0].menu != "main" || entries[0].weight != 5 {
+		t.Errorf("Expected the page in the main menu with weight 5. Got: %v, %v", entries, err)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// GeneratorConfig creates a page for each record in a data file
+type GeneratorConfig struct {
+	// Data is the data file, relative to the data directory (e.g. products.json)
+	// It must hold a list of records, or a map of records keyed by their slug
+	Data string `yaml:"data"`
+	// Template is the template used to render each page
+	Template string `yaml:"template"`
+	// Permalink is the URL pattern for the pages, e.g. /products/:slug/
+	// Patterns can use the usual tokens and any field of the record, e.g. :id
+	Permalink string `yaml:"permalink"`
+	// Section is the content type of the pages, listed at /<section>/
+	// Defaults to the name of the data file
+	Section string `yaml:"section"`
+	// Title is the field used for the page title - defaults to title, then name
+	Title string `yaml:"title"`
+	// Slug is the field used for the slug - defaults to slug, then the slugified title
+	Slug string `yaml:"slug"`
+}
+
+// **********  Private Generator Methods  **********
+
+// Generate a page for each record in the data files set up in the config.
+// The pages are added to the directory map, so they are rendered, listed and
+// linked the same way as the markdown pages.
+func (b *Builder) generatePages(dirsMap map[string]DirectoryInfo, data map[string]interface{}) error {
+	for _, generator := range config.Generators {
+		files, err := b.generatorPages(generator, data)
+		if err != nil {
+			errorPath := path.Join(dataDir, strings.TrimPrefix(filepath.ToSlash(generator.Data), dataDir+"/"))
+			if err := b.addError(errorPath, "", err); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(files) == 0 {
+			continue
+		}
+
+		// Add the pages to the directory of their section, so the section is listed
+		dirKey := filepath.Join(b.contentDir, files[0].ContentType)
+		dirInfo, exists := dirsMap[dirKey]
+		if !exists {
+			dirInfo = DirectoryInfo{Path: files[0].ContentType}
+		}
+		dirInfo.Files = append(dirInfo.Files, files...)
+		dirInfo.NumFiles += len(files)
+		dirsMap[dirKey] = dirInfo
+	}
+
+	return nil
+}
+
+// Build the pages for the records of a single generator
+func (b *Builder) generatorPages(generator GeneratorConfig, data map[string]interface{}) ([]FileInfo, error) {
+	if generator.Data == "" || generator.Template == "" || generator.Permalink == "" {
+		return nil, fmt.Errorf("generators need a data file, a template and a permalink")
+	}
+	// The data file can be given with or without the data directory
+	generator.Data = strings.TrimPrefix(filepath.ToSlash(generator.Data), dataDir+"/")
+
+	records, err := generatorRecords(generator.Data, data)
+	if err != nil {
+		return nil, err
+	}
+
+	section := generator.Section
+	if section == "" {
+		section = strings.TrimSuffix(path.Base(generator.Data), path.Ext(generator.Data))
+	}
+	info, err := os.Stat(filepath.Join(b.rootPath, dataDir, filepath.FromSlash(generator.Data)))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []FileInfo
+	for i, record := range records {
+		metaData := make(map[string]interface{}, len(record.fields)+2)
+		for key, value := range record.fields {
+			metaData[key] = value
+		}
+
+		title := recordField(record.fields, generator.Title, "title", "name")
+		slug := recordField(record.fields, generator.Slug, "slug")
+		if slug == "" {
+			slug = record.key
+		}
+		if slug == "" {
+			slug = slugify(title)
+		}
+		if slug == "" {
+			slug = fmt.Sprint(i + 1)
+		}
+		if title == "" {
+			title = slug
+		}
+		metaData["title"] = title
+		metaData["template"] = generator.Template
+
+		// Records can use their own fields in the permalink
+		urlDate, err := permalinkDate(generator.Permalink, metaData)
+		if err != nil {
+			return nil, fmt.Errorf("record %s: %w", slug, err)
+		}
+		values := permalinkValues(urlDate, section, slug, title)
+		for key, value := range record.fields {
+			if _, exists := values[":"+key]; !exists {
+				values[":"+key] = slugify(fmt.Sprint(value))
+			}
+		}
+		permalink, err := expandPermalink(generator.Permalink, values)
+		if err != nil {
+			return nil, err
+		}
+		// The modification time of the data file is only used to sort records without a date
+		date, err := pageDate(metaData, info.ModTime())
+		if err != nil {
+			date = info.ModTime()
+		}
+
+		files = append(files, FileInfo{
+			Name:        slug,
+			Path:        filepath.ToSlash(filepath.Join(dataDir, generator.Data)) + "#" + slug,
+			OutputPath:  permalink,
+			FileType:    strings.TrimPrefix(path.Ext(generator.Data), "."),
+			ContentType: section,
+			MetaData:    metaData,
+			Language:    config.DefaultLanguage,
+			Date:        date,
+			// A content field is rendered as markdown for the page content
+			source: metaString(record.fields, "content"),
+		})
+	}
+
+	return files, nil
+}
+
+// A record from a data file used to generate a page
+type generatorRecord struct {
+	key    string // The key of the record when the data file holds a map
+	fields map[string]interface{}
+}
+
+// Find the records of a data file in the data tree
+func generatorRecords(dataFile string, data map[string]interface{}) ([]generatorRecord, error) {
+	keys := strings.Split(strings.TrimSuffix(filepath.ToSlash(dataFile), path.Ext(dataFile)), "/")
+	var value interface{} = data
+	for _, key := range keys {
+		table, isMap := value.(map[string]interface{})
+		if !isMap {
+			return nil, fmt.Errorf("data file %s not found", dataFile)
+		}
+		if value = table[key]; value == nil {
+			return nil, fmt.Errorf("data file %s not found", dataFile)
+		}
+	}
+
+	var records []generatorRecord
+	switch value := value.(type) {
+	case []interface{}:
+		for i, item := range value {
+			fields, isMap := item.(map[string]interface{})
+			if !isMap {
+				return nil, fmt.Errorf("record %d in %s is not a map of fields", i+1, dataFile)
+			}
+			records = append(records, generatorRecord{fields: fields})
+		}
+	case map[string]interface{}:
+		for _, key := range sortedKeys(value) {
+			fields, isMap := value[key].(map[string]interface{})
+			if !isMap {
+				return nil, fmt.Errorf("record %s in %s is not a map of fields", key, dataFile)
+			}
+			records = append(records, generatorRecord{key: key, fields: fields})
+		}
+	default:
+		return nil, fmt.Errorf("data file %s should hold a list or a map of records", dataFile)
+	}
+
+	return records, nil
+}
+
+// Returns the first field of a record with a value, trying the configured field first
+func recordField(fields map[string]interface{}, configured string, defaults ...string) string {
+	names := defaults
+	if configured != "" {
+		names = []string{configured}
+	}
+	for _, name := range names {
+		if value := metaString(fields, name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
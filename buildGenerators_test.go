@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuilder_GeneratePages(t *testing.T) {
+	restoreConfig(t)
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "data"), 0755)
+	os.WriteFile(filepath.Join(root, "data", "products.json"), []byte("[]"), 0644)
+
+	config.UglyURLs = false
+	config.Generators = []GeneratorConfig{
+		{Data: "data/products.json", Template: "product.tmpl", Permalink: "/shop/:id/"},
+	}
+
+	b := Builder{rootPath: root, contentDir: filepath.Join(root, "content")}
+	data := map[string]interface{}{
+		"products": []interface{}{
+			map[string]interface{}{"id": "W-1", "name": "Widget", "content": "A *fine* widget"},
+			map[string]interface{}{"id": "G-2", "title": "Gadget", "slug": "the-gadget"},
+			map[string]interface{}{"id": 1234567, "title": "Gizmo"},
+		},
+	}
+	dirsMap := make(map[string]DirectoryInfo)
+	if err := b.generatePages(dirsMap, data); err != nil {
+		t.Fatalf("generatePages returned an error: %v", err)
+	}
+
+	dirInfo, exists := dirsMap[filepath.Join(root, "content", "products")]
+	if !exists || dirInfo.NumFiles != 3 {
+		t.Fatalf("Expected 3 pages in the products section. Got: %v", dirsMap)
+	}
+
+	widget, gadget := dirInfo.Files[0], dirInfo.Files[1]
+	if widget.OutputPath != "/shop/w-1/" || widget.Name != "widget" || widget.MetaData["title"] != "Widget" {
+		t.Errorf("Widget page mismatch. Got: %s %s %v", widget.OutputPath, widget.Name, widget.MetaData["title"])
+	}
+	if widget.MetaData["template"] != "product.tmpl" || widget.source != "A *fine* widget" {
+		t.Errorf("Widget template or content mismatch. Got: %v %q", widget.MetaData["template"], widget.source)
+	}
+	if gadget.Name != "the-gadget" || gadget.ContentType != "products" {
+		t.Errorf("Gadget page mismatch. Got: %s %s", gadget.Name, gadget.ContentType)
+	}
+	if gizmo := dirInfo.Files[2]; gizmo.OutputPath != "/shop/1234567/" {
+		t.Errorf("Gizmo permalink mismatch. Got: %s, Want: /shop/1234567/", gizmo.OutputPath)
+	}
+
+	// Missing data files are reported against the data file
+	config.Generators[0].Data = "missing.json"
+	if err := b.generatePages(make(map[string]DirectoryInfo), data); err != nil {
+		t.Fatalf("generatePages returned an error: %v", err)
+	}
+	if len(b.errors) != 1 || b.errors[0].File != "data/missing.json" {
+		t.Errorf("Expected an error for data/missing.json. Got: %v", b.errors)
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LanguageConfig holds the settings for one language of a multilingual site
+type LanguageConfig struct {
+	// Name is the name of the language shown to readers, e.g. Español
+	Name string `yaml:"name"`
+	// Weight orders the languages, lowest first
+	Weight int `yaml:"weight"`
+	// Sitename replaces the site name on the pages in the language
+	Sitename string `yaml:"sitename"`
+	// Menus replace the menus in the config on the pages in the language
+	Menus map[string]Menu `yaml:"menus"`
+}
+
+// Language is a language of the site, used in templates as .Site.Languages
+type Language struct {
+	Code string // The language code, e.g. es
+	Name string // The name of the language, e.g. Español
+	URL  string // The home page of the language, e.g. /es/
+}
+
+// The directory (relative to the root) that holds the translated strings
+const i18nDir = "i18n"
+
+// **********  Public FileInfo Methods  **********
+
+// LanguageName returns the name of the language of the page, e.g. Español
+// Used in templates to link to translations
+func (f FileInfo) LanguageName() string {
+	if language, exists := config.Languages[f.Language]; exists && language.Name != "" {
+		return language.Name
+	}
+	return f.Language
+}
+
+// **********  Private Language Methods  **********
+
+// Link the pages that are translations of each other. Pages are translations
+// when they have the same path without the language, e.g. about.md and
+// about.es.md, or the same `translationKey:` in their front matter.
+func (b *Builder) linkTranslations(dirsMap map[string]DirectoryInfo) {
+	if len(config.Languages) == 0 {
+		return
+	}
+
+	translations := make(map[string][]FileInfo)
+	for _, dirInfo := range dirsMap {
+		for _, file := range dirInfo.Files {
+			key := translationKey(file)
+			translations[key] = append(translations[key], file)
+		}
+	}
+	for _, files := range translations {
+		sortByLanguage(files)
+	}
+
+	for dirKey, dirInfo := range dirsMap {
+		for i, file := range dirInfo.Files {
+			var others []FileInfo
+			for _, translation := range translations[translationKey(file)] {
+				if translation.Language != file.Language {
+					others = append(others, translation)
+				}
+			}
+			dirInfo.Files[i].Translations = others
+		}
+		dirsMap[dirKey] = dirInfo
+	}
+}
+
+// Load the translated strings for each language from i18n/<lang>.yml
+// Nested keys are joined with a dot, e.g. nav.home
+func (b *Builder) loadTranslations() (map[string]map[string]string, error) {
+	translations := make(map[string]map[string]string)
+	root := filepath.Join(b.rootPath, i18nDir)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return translations, nil
+		}
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		extension := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || !isDataFile(extension) || extension == ".csv" {
+			continue
+		}
+
+		lang := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		value, err := b.loadDataFile(filepath.Join(root, entry.Name()), extension)
+		if err != nil {
+			if err := b.recordError(dataFileError(path.Join(i18nDir, entry.Name()), err)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if translations[lang] == nil {
+			translations[lang] = make(map[string]string)
+		}
+		flattenTranslations(translations[lang], "", value)
+	}
+
+	return translations, nil
+}
+
+// Returns the translation of a string in the given language, falling back to
+// the default language and then the key itself. Any arguments fill in the
+// verbs of the string, e.g. "%d comments".
+func (b *Builder) translate(lang string, key string, args ...interface{}) string {
+	text, exists := b.translations[lang][key]
+	if !exists {
+		text, exists = b.translations[config.DefaultLanguage][key]
+	}
+	if !exists {
+		text = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// Returns the languages of the site, sorted by weight and code
+func siteLanguages() []Language {
+	codes := languageCodes()
+	languages := make([]Language, 0, len(codes))
+	for _, code := range codes {
+		name := config.Languages[code].Name
+		if name == "" {
+			name = code
+		}
+		languages = append(languages, Language{Code: code, Name: name, URL: languageHome(code)})
+	}
+	return languages
+}
+
+// Returns the codes of the languages of the site, sorted by weight and code.
+// Sites without languages only have the default language.
+func languageCodes() []string {
+	codes := []string{config.DefaultLanguage}
+	for code := range config.Languages {
+		if code != config.DefaultLanguage {
+			codes = append(codes, code)
+		}
+	}
+	sort.SliceStable(codes, func(i, j int) bool {
+		weightI, weightJ := config.Languages[codes[i]].Weight, config.Languages[codes[j]].Weight
+		if weightI != weightJ {
+			return weightI < weightJ
+		}
+		return codes[i] < codes[j]
+	})
+	return codes
+}
+
+// Check if a code is one of the languages of a multilingual site
+func isLanguage(code string) bool {
+	if len(config.Languages) == 0 {
+		return false
+	}
+	_, exists := config.Languages[code]
+	return exists || code == config.DefaultLanguage
+}
+
+// Returns the language of a content file, with the directory, section and
+// file name once the language is taken out of them. The language comes from
+// a suffix on the file name (about.es.md) or a top level directory
+// (es/about.md), with the suffix used when a file has both.
+func contentLanguage(dir string, fileName string) (string, string, string, string) {
+	lang := config.DefaultLanguage
+
+	dir = filepath.ToSlash(dir)
+	components := strings.Split(dir, "/")
+	if isLanguage(components[0]) {
+		lang = components[0]
+		dir = strings.Join(components[1:], "/")
+	}
+	if suffix := path.Ext(fileName); suffix != "" && isLanguage(suffix[1:]) {
+		lang = suffix[1:]
+		fileName = strings.TrimSuffix(fileName, suffix)
+	}
+
+	section := ""
+	if dir != "" {
+		section = strings.Split(dir, "/")[0]
+	}
+	return lang, filepath.FromSlash(dir), section, fileName
+}
+
+// Returns the language of a directory in the directory map from its path
+func dirLanguage(dir string) string {
+	first := strings.Split(filepath.ToSlash(dir), "/")[0]
+	if isLanguage(first) {
+		return first
+	}
+	return config.DefaultLanguage
+}
+
+// Returns the directory the pages of a language are written to, relative to
+// the output directory. The default language is written at the root.
+func languageDir(lang string) string {
+	if lang == "" || lang == config.DefaultLanguage {
+		return ""
+	}
+	return lang
+}
+
+// Returns the URL of the home page of a language, e.g. /es/
+func languageHome(lang string) string {
+	return listPermalink(languageDir(lang))
+}
+
+// Returns the key that links the translations of a page
+func translationKey(file FileInfo) string {
+	if key := metaString(file.MetaData, "translationKey"); key != "" {
+		return key
+	}
+	relPath := filepath.ToSlash(file.Path)
+	dir := path.Dir(relPath)
+	if dir == "." {
+		dir = ""
+	}
+	_, dir, _, fileName := contentLanguage(dir, strings.TrimSuffix(path.Base(relPath), path.Ext(relPath)))
+	return path.Join(filepath.ToSlash(dir), fileName)
+}
+
+// Sort pages by the weight of their language, then the language code
+func sortByLanguage(files []FileInfo) {
+	order := make(map[string]int)
+	for i, code := range languageCodes() {
+		order[code] = i
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return order[files[i].Language] < order[files[j].Language]
+	})
+}
+
+// Add the strings in a translation file to the table, joining nested keys
+func flattenTranslations(table map[string]string, prefix string, value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenTranslations(table, key, item)
+		}
+	case nil:
+	default:
+		table[prefix] = fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestContentLanguage(t *testing.T) {
+	restoreConfig(t)
+	config.DefaultLanguage = "en"
+	config.Languages = map[string]LanguageConfig{"en": {}, "es": {Name: "Español"}}
+
+	tests := []struct {
+		dir, fileName                    string
+		lang, wantDir, section, wantName string
+	}{
+		{"", "about", "en", "", "", "about"},
+		{"", "about.es", "es", "", "", "about"},
+		{"post", "hello.es", "es", "post", "post", "hello"},
+		{"es/post", "hello", "es", "post", "post", "hello"},
+		{"en/post", "hello", "en", "post", "post", "hello"},
+		{"es", "index", "es", "", "", "index"},
+		{"post", "v1.2", "en", "post", "post", "v1.2"},
+	}
+
+	for _, tt := range tests {
+		lang, dir, section, name := contentLanguage(tt.dir, tt.fileName)
+		if lang != tt.lang || dir != tt.wantDir || section != tt.section || name != tt.wantName {
+			t.Errorf("contentLanguage(%q, %q) = %q, %q, %q, %q, want %q, %q, %q, %q", tt.dir, tt.fileName,
+				lang, dir, section, name, tt.lang, tt.wantDir, tt.section, tt.wantName)
+		}
+	}
+
+	// Sites without languages don't treat suffixes or directories as languages
+	config.Languages = nil
+	if lang, dir, _, name := contentLanguage("es", "about.es"); lang != "en" || dir != "es" || name != "about.es" {
+		t.Errorf("Expected no language handling without languages. Got: %q %q %q", lang, dir, name)
+	}
+}
+
+func TestBuilder_LinkTranslations(t *testing.T) {
+	restoreConfig(t)
+	config.DefaultLanguage = "en"
+	config.Languages = map[string]LanguageConfig{"en": {Weight: 1}, "es": {Weight: 2}, "fr": {Weight: 3}}
+
+	dirsMap := map[string]DirectoryInfo{
+		"content": {Files: []FileInfo{
+			{Path: "about.md", Language: "en", OutputPath: "/about/"},
+			{Path: "contact.md", Language: "en", OutputPath: "/contact/", MetaData: map[string]interface{}{"translationKey": "contact"}},
+		}},
+		"content/es": {Files: []FileInfo{
+			{Path: "about.es.md", Language: "es", OutputPath: "/es/about/"},
+			{Path: "contacto.es.md", Language: "es", OutputPath: "/es/contacto/", MetaData: map[string]interface{}{"translationKey": "contact"}},
+		}},
+		"content/fr": {Files: []FileInfo{
+			{Path: "fr/about.md", Language: "fr", OutputPath: "/fr/about/"},
+		}},
+	}
+
+	b := Builder{}
+	b.linkTranslations(dirsMap)
+
+	about := dirsMap["content"].Files[0]
+	if len(about.Translations) != 2 || about.Translations[0].OutputPath != "/es/about/" || about.Translations[1].OutputPath != "/fr/about/" {
+		t.Errorf("Expected the es and fr translations in order. Got: %v", about.Translations)
+	}
+	contact := dirsMap["content/es"].Files[1]
+	if len(contact.Translations) != 1 || contact.Translations[0].OutputPath != "/contact/" {
+		t.Errorf("Expected the translation with the same translationKey. Got: %v", contact.Translations)
+	}
+}
+
+func TestBuilder_Translate(t *testing.T) {
+	restoreConfig(t)
+	config.DefaultLanguage = "en"
+	b := Builder{translations: map[string]map[string]string{
+		"en": {"readMore": "Read more", "comments": "%d comments"},
+		"es": {"readMore": "Leer más"},
+	}}
+
+	if got := b.translate("es", "readMore"); got != "Leer más" {
+		t.Errorf("Got: %q, Want: Leer más", got)
+	}
+	if got := b.translate("es", "comments", 3); got != "3 comments" {
+		t.Errorf("Expected the default language as a fallback. Got: %q", got)
+	}
+	if got := b.translate("es", "missing"); got != "missing" {
+		t.Errorf("Expected the key for missing strings. Got: %q", got)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The ways broken references to other content can be handled
+const (
+	refLinksWarn   = "warn"   // Log a warning for each broken reference
+	refLinksError  = "error"  // Fail the build when there are broken references
+	refLinksIgnore = "ignore" // Leave broken references as they are
+)
+
+// **********  Private Link Methods  **********
+
+// Build the index of content paths to output paths used to resolve links
+func (b *Builder) buildLinkIndex(dirsMap map[string]DirectoryInfo) {
+	b.linkIndex = make(map[string]string)
+	b.brokenRefs = nil
+
+	for _, dirInfo := range dirsMap {
+		for _, file := range dirInfo.Files {
+			b.linkIndex[filepath.ToSlash(file.Path)] = file.OutputPath
+		}
+	}
+}
+
+// Resolve a markdown link to a content file into the file's output path.
+// Links that don't point to a markdown file are returned as they are.
+func (b *Builder) resolveLink(page FileInfo, destination string) string {
+	parsed, err := url.Parse(destination)
+	if err != nil || parsed.Scheme != "" || parsed.Host != "" {
+		return destination
+	}
+	if !strings.HasSuffix(parsed.Path, ".md") {
+		return destination
+	}
+
+	outputPath, exists := b.lookupRef(page, parsed.Path)
+	if !exists {
+		b.addBrokenRef(page, destination)
+		return destination
+	}
+	if parsed.Fragment != "" {
+		outputPath += "#" + parsed.Fragment
+	}
+
+	return outputPath
+}
+
+// Resolve a reference to a content file into the file's output path.
+// Used by the ref template function and shortcode.
+func (b *Builder) resolveRef(page FileInfo, target string) (string, error) {
+	targetPath, fragment, _ := strings.Cut(target, "#")
+	if filepath.Ext(targetPath) == "" {
+		targetPath += ".md"
+	}
+
+	outputPath, exists := b.lookupRef(page, targetPath)
+	if !exists {
+		b.addBrokenRef(page, target)
+		return target, nil
+	}
+	if fragment != "" {
+		outputPath += "#" + fragment
+	}
+
+	return outputPath, nil
+}
+
+// Look up the output path for a content path.
+// Paths starting with "/" are relative to the content directory, other paths
+// are relative to the page first and then to the content directory.
+func (b *Builder) lookupRef(page FileInfo, target string) (string, bool) {
+	target, _ = url.PathUnescape(target)
+	if strings.HasPrefix(target, "/") {
+		outputPath, exists := b.linkIndex[path.Clean(strings.TrimPrefix(target, "/"))]
+		return outputPath, exists
+	}
+
+	pageDir := path.Dir(filepath.ToSlash(page.Path))
+	if outputPath, exists := b.linkIndex[path.Join(pageDir, target)]; exists {
+		return outputPath, true
+	}
+	outputPath, exists := b.linkIndex[path.Clean(target)]
+	return outputPath, exists
+}
+
+// Record a reference to content that doesn't exist
+func (b *Builder) addBrokenRef(page FileInfo, target string) {
+	if config.RefLinks == refLinksIgnore {
+		return
+	}
+	b.brokenRefs = append(b.brokenRefs, BuildError{
+		File: page.Path,
+		Err:  fmt.Errorf("%q does not match any content", target),
+	})
+}
+
+// Report the broken references found while rendering content.
+// They are build errors when the config asks for broken references to fail the build.
+func (b *Builder) reportBrokenRefs() error {
+	sort.SliceStable(b.brokenRefs, func(i, j int) bool {
+		return b.brokenRefs[i].File < b.brokenRefs[j].File
+	})
+
+	for _, brokenRef := range b.brokenRefs {
+		if config.RefLinks != refLinksError {
+			logger.Warn("Broken reference in %s", brokenRef.Error())
+			continue
+		}
+		if err := b.addError(brokenRef.File, "", brokenRef.Err); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestBuilder_ResolveLink(t *testing.T) {
+	b := Builder{
+		linkIndex: map[string]string{
+			"index.md":           "/index.html",
+			"post/tutorial-1.md": "/post/tutorial-1.html",
+			"post/tutorial-2.md": "/post/tutorial-2.html",
+		},
+	}
+	page := FileInfo{Path: "post/tutorial-1.md"}
+
+	tests := []struct {
+		destination string
+		want        string
+	}{
+		{"tutorial-2.md", "/post/tutorial-2.html"},
+		{"./tutorial-2.md#part-2", "/post/tutorial-2.html#part-2"},
+		{"../index.md", "/index.html"},
+		{"/post/tutorial-2.md", "/post/tutorial-2.html"},
+		{"https://example.com/readme.md", "https://example.com/readme.md"},
+		{"/post/tutorial-2.html", "/post/tutorial-2.html"},
+		{"tutorial-9.md", "tutorial-9.md"},
+	}
+	for _, tt := range tests {
+		if got := b.resolveLink(page, tt.destination); got != tt.want {
+			t.Errorf("resolveLink(%q) mismatch. Got: %q, Want: %q", tt.destination, got, tt.want)
+		}
+	}
+
+	// Only the missing markdown file is reported
+	if len(b.brokenRefs) != 1 {
+		t.Errorf("Broken reference count mismatch. Got: %d, Want: 1", len(b.brokenRefs))
+	}
+}
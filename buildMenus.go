@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MenuItem is a link in a navigation menu
+type MenuItem struct {
+	Name     string `yaml:"name"`     // The text of the link
+	URL      string `yaml:"url"`      // The URL of the link
+	Weight   int    `yaml:"weight"`   // Items are sorted by weight, items without a weight go last
+	Children Menu   `yaml:"children"` // The nested menu items
+}
+
+// Menu is a list of menu items, sorted by weight
+type Menu []MenuItem
+
+// The name of the front matter key used to add a page to menus
+const menuKey = "menu"
+
+// **********  Public MenuItem Methods  **********
+
+// IsActive checks if the menu item links to the page being rendered
+// Used in templates as {{ if .IsActive $.Page }}
+func (m MenuItem) IsActive(page FileInfo) bool {
+	return page.OutputPath != "" && sameURL(m.URL, page.OutputPath)
+}
+
+// HasActiveChild checks if any of the nested menu items link to the page being rendered
+func (m MenuItem) HasActiveChild(page FileInfo) bool {
+	for _, child := range m.Children {
+		if child.IsActive(page) || child.HasActiveChild(page) {
+			return true
+		}
+	}
+	return false
+}
+
+// **********  Private Menu Methods  **********
+
+// Build the menus from the config and the `menu:` front matter of each page.
+// Front matter can name a menu (menu: main), a list of menus, or a map of
+// menus with an optional weight, title and parent for each:
+//
+//	menu:
+//	  main:
+//	    weight: 20
+//	    title: About us
+//	    parent: Company
+//
+// Each language has its own menus, from the language's config or the site's,
+// with the pages in that language.
+func (b *Builder) buildMenus(dirsMap map[string]DirectoryInfo, lang string) (map[string]Menu, error) {
+	configMenus := config.Menus
+	if languageMenus := config.Languages[lang].Menus; languageMenus != nil {
+		configMenus = languageMenus
+	}
+	menus := make(map[string]Menu)
+	for name, items := range configMenus {
+		menus[name] = append(Menu{}, items...)
+	}
+
+	// Collect the pages in a stable order so items with the same weight and name
+	// always come out the same way
+	var files []FileInfo
+	for _, dirInfo := range dirsMap {
+		files = append(files, dirInfo.Files...)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	for _, file := range files {
+		if file.Language != lang {
+			continue
+		}
+		entries, err := menuEntries(file.MetaData[menuKey])
+		if err != nil {
+			if err := b.addError(file.Path, "", err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		for _, entry := range entries {
+			item := MenuItem{
+				Name:   entry.title,
+				URL:    file.OutputPath,
+				Weight: entry.weight,
+			}
+			if item.Name == "" {
+				item.Name = metaString(file.MetaData, "title")
+			}
+			if item.Name == "" {
+				item.Name = file.Name
+			}
+
+			if entry.parent == "" {
+				menus[entry.menu] = append(menus[entry.menu], item)
+				continue
+			}
+			if !menus[entry.menu].addChild(entry.parent, item) {
+				err := fmt.Errorf("menu parent %q not found in the %s menu", entry.parent, entry.menu)
+				if err := b.addError(file.Path, "", err); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	for name := range menus {
+		menus[name].sort()
+	}
+	return menus, nil
+}
+
+// Add an item to the children of the item with the given name
+// Returns false when there is no item with that name
+func (m Menu) addChild(parent string, item MenuItem) bool {
+	for i := range m {
+		if m[i].Name == parent {
+			m[i].Children = append(m[i].Children, item)
+			return true
+		}
+		if m[i].Children.addChild(parent, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sort the menu and its children by weight, then by name
+// Items without a weight are sorted after the weighted items
+func (m Menu) sort() {
+	sort.SliceStable(m, func(i, j int) bool {
+		if m[i].Weight != m[j].Weight {
+			if m[i].Weight == 0 || m[j].Weight == 0 {
+				return m[j].Weight == 0
+			}
+			return m[i].Weight < m[j].Weight
+		}
+		return m[i].Name < m[j].Name
+	})
+	for _, item := range m {
+		item.Children.sort()
+	}
+}
+
+// A menu a page adds itself to with front matter
+type menuEntry struct {
+	menu   string
+	title  string
+	weight int
+	parent string
+}
+
+// Read the menus a page adds itself to from its `menu:` front matter
+func menuEntries(value interface{}) ([]menuEntry, error) {
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if value == "" {
+			return nil, nil
+		}
+		return []menuEntry{{menu: value}}, nil
+	case []interface{}:
+		var entries []menuEntry
+		for _, name := range value {
+			entries = append(entries, menuEntry{menu: fmt.Sprint(name)})
+		}
+		return entries, nil
+	case map[interface{}]interface{}, map[string]interface{}:
+		// TOML and JSON front matter have string keys, YAML any keys
+		var entries []menuEntry
+		for name, options := range stringKeys(value).(map[string]interface{}) {
+			entry := menuEntry{menu: name}
+			if options, isMap := options.(map[string]interface{}); isMap {
+				entry.title = fmt.Sprint(valueOrEmpty(options["title"]))
+				entry.parent = fmt.Sprint(valueOrEmpty(options["parent"]))
+				if weight, exists := options["weight"]; exists {
+					number, isInt := weight.(int)
+					if !isInt {
+						return nil, fmt.Errorf("menu weight should be a number, got %v", weight)
+					}
+					entry.weight = number
+				}
+			}
+			entries = append(entries, entry)
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].menu < entries[j].menu })
+		return entries, nil
+	}
+	return nil, fmt.Errorf("menu should be a menu name, a list or a map of menus, got %v", value)
+}
+
+// Check if two URLs point to the same page, so /about, /about/, /about.html
+// and /about/index.html all match
+func sameURL(a string, b string) bool {
+	return trimURL(a) == trimURL(b)
+}
+
+// Strip the parts of a URL that don't change the page it points to
+func trimURL(url string) string {
+	url = strings.TrimSuffix(url, "index.html")
+	url = strings.TrimSuffix(url, ".html")
+	url = strings.TrimSuffix(url, "/")
+	if url == "" {
+		return "/"
+	}
+	return url
+}
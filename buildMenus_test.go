@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestBuilder_BuildMenus(t *testing.T) {
+	restoreConfig(t)
+	config.Menus = map[string]Menu{
+		"main": {
+			{Name: "Home", URL: "/", Weight: 1},
+			{Name: "Company", URL: "/company/", Weight: 30},
+		},
+	}
+
+	b := Builder{}
+	dirsMap := map[string]DirectoryInfo{
+		"content": {Files: []FileInfo{
+			{Path: "about.md", Name: "about", OutputPath: "/about/", MetaData: map[string]interface{}{
+				"title": "About",
+				"menu":  "main",
+			}},
+			{Path: "blog.md", Name: "blog", OutputPath: "/blog/", MetaData: map[string]interface{}{
+				"title": "Blog",
+				"menu": map[interface{}]interface{}{
+					"main":   map[interface{}]interface{}{"weight": 10, "title": "Our blog"},
+					"footer": nil,
+				},
+			}},
+			{Path: "team.md", Name: "team", OutputPath: "/team/", MetaData: map[string]interface{}{
+				"menu": map[interface{}]interface{}{
+					"main": map[interface{}]interface{}{"parent": "Company"},
+				},
+			}},
+		}},
+	}
+
+	menus, err := b.buildMenus(dirsMap, "")
+	if err != nil {
+		t.Fatalf("buildMenus returned an error: %v", err)
+	}
+
+	// Items are sorted by weight, with unweighted items last
+	var names []string
+	for _, item := range menus["main"] {
+		names = append(names, item.Name)
+	}
+	want := []string{"Home", "Our blog", "Company", "About"}
+	if len(names) != len(want) {
+		t.Fatalf("Main menu mismatch. Got: %v, Want: %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("Main menu mismatch. Got: %v, Want: %v", names, want)
+			break
+		}
+	}
+
+	company := menus["main"][2]
+	if len(company.Children) != 1 || company.Children[0].Name != "team" {
+		t.Errorf("Company children mismatch. Got: %v", company.Children)
+	}
+	if len(menus["footer"]) != 1 || menus["footer"][0].Name != "Blog" {
+		t.Errorf("Footer menu mismatch. Got: %v", menus["footer"])
+	}
+
+	// Active items match the page whatever the URL style
+	page := FileInfo{OutputPath: "/team/index.html"}
+	if !company.HasActiveChild(page) || company.IsActive(page) {
+		t.Error("Expected the company item to have an active child but not be active")
+	}
+	if !(MenuItem{URL: "/"}).IsActive(FileInfo{OutputPath: "/index.html"}) {
+		t.Error("Expected the home item to be active on /index.html")
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The front matter keys used for the date in permalinks, in order of preference
+var permalinkDateKeys = []string{"publish_date", "date"}
+
+// Matches the tokens in a permalink pattern, e.g. :year or :slug
+var permalinkTokenPattern = regexp.MustCompile(`:[a-zA-Z][a-zA-Z0-9_]*`)
+
+// The extensions a permalink can end in. Other dots, like in go-1.21, are
+// part of the slug.
+var permalinkExtensions = map[string]bool{".html": true, ".htm": true, ".xml": true}
+
+// Matches the characters that are replaced with a dash in a slug
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// **********  Private Permalink Methods  **********
+
+// Returns the permalink for a content file. The `url:` front matter replaces
+// the permalink, otherwise the pattern for the section is expanded, with the
+// `slug:` front matter in place of the file name. Files without a pattern keep
+// their path in the content directory.
+// Unless uglyURLs is set, permalinks end in a slash so pages are written to
+// index.html in a directory of their own.
+// Pages in languages other than the default are placed under /<lang>/.
+func (b *Builder) permalink(lang string, dir string, section string, fileName string, metaData map[string]interface{}) (string, error) {
+	if url := metaString(metaData, "url"); url != "" {
+		return normalizePermalink(url), nil
+	}
+
+	permalink, err := b.sectionPermalink(dir, section, fileName, metaData)
+	if err != nil || languageDir(lang) == "" {
+		return permalink, err
+	}
+	return "/" + languageDir(lang) + permalink, nil
+}
+
+// Returns the permalink of a page from the pattern for its section
+func (b *Builder) sectionPermalink(dir string, section string, fileName string, metaData map[string]interface{}) (string, error) {
+	slug := metaString(metaData, "slug")
+	if slug == "" {
+		slug = fileName
+	}
+
+	// Index files list their section, so they always stay in their directory
+	if fileName == "index" {
+		if config.UglyURLs {
+			return path.Join("/", filepath.ToSlash(dir), "index.html"), nil
+		}
+		return normalizePermalink(path.Join("/", filepath.ToSlash(dir)) + "/"), nil
+	}
+
+	pattern, exists := config.Permalinks[section]
+	if !exists || section == "" {
+		return normalizePermalink(path.Join("/", filepath.ToSlash(dir), slug)), nil
+	}
+
+	date, err := permalinkDate(pattern, metaData)
+	if err != nil {
+		return "", err
+	}
+	title := metaString(metaData, "title")
+	if title == "" {
+		title = fileName
+	}
+
+	values := permalinkValues(date, section, slug, title)
+	values[":filename"] = fileName
+	return expandPermalink(pattern, values)
+}
+
+// Returns the values of the tokens every permalink pattern can use
+func permalinkValues(date time.Time, section string, slug string, title string) map[string]string {
+	return map[string]string{
+		":year":    date.Format("2006"),
+		":month":   date.Format("01"),
+		":day":     date.Format("02"),
+		":section": section,
+		":slug":    slug,
+		":title":   slugify(title),
+	}
+}
+
+// Replace the tokens in a permalink pattern with their values
+func expandPermalink(pattern string, values map[string]string) (string, error) {
+	var unknownToken string
+	expanded := permalinkTokenPattern.ReplaceAllStringFunc(pattern, func(token string) string {
+		value, known := values[token]
+		if !known {
+			unknownToken = token
+		}
+		return value
+	})
+	if unknownToken != "" {
+		return "", fmt.Errorf("unknown token %s in permalink pattern %q", unknownToken, pattern)
+	}
+
+	return normalizePermalink(expanded), nil
+}
+
+// Report files that have the same permalink, since only one of them would be written
+func (b *Builder) checkPermalinks(dirsMap map[string]DirectoryInfo) error {
+	permalinks := make(map[string][]string)
+	for _, dirInfo := range dirsMap {
+		for _, file := range dirInfo.Files {
+			permalinks[file.OutputPath] = append(permalinks[file.OutputPath], file.Path)
+		}
+	}
+
+	for _, permalink := range sortedKeys(permalinks) {
+		files := permalinks[permalink]
+		if len(files) < 2 {
+			continue
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			err := fmt.Errorf("permalink %s is also used by %s", permalink, strings.Join(otherFiles(files, file), ", "))
+			if err := b.addError(file, "", err); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns the permalink of the index page listing the files in a directory
+func listPermalink(dir string) string {
+	permalink := path.Join("/", filepath.ToSlash(dir))
+	if config.UglyURLs {
+		return path.Join(permalink, "index.html")
+	}
+	return normalizePermalink(permalink + "/")
+}
+
+// Returns the path of the file in the output directory for a permalink
+// Permalinks ending in a slash are written to index.html in that directory
+func (b *Builder) outputFilePath(permalink string) string {
+	if strings.HasSuffix(permalink, "/") {
+		permalink += "index.html"
+	}
+	return filepath.Join(b.outputDir, filepath.FromSlash(strings.TrimPrefix(permalink, "/")))
+}
+
+// Returns the date of a page from its front matter, or the modification time
+// of the file when it doesn't have one
+func pageDate(metaData map[string]interface{}, modTime time.Time) (time.Time, error) {
+	for _, key := range permalinkDateKeys {
+		value, exists := metaData[key]
+		if !exists || value == nil || value == "" {
+			continue
+		}
+		if date, isTime := value.(time.Time); isTime {
+			return date, nil
+		}
+		// Only the date is used, so any time after it is ignored
+		text := strings.TrimSpace(fmt.Sprint(value))
+		if len(text) > len(defaultDateFormat) {
+			text = text[:len(defaultDateFormat)]
+		}
+		date, err := time.Parse(defaultDateFormat, text)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s should be a date formatted as %s, got %q", key, defaultDateFormat, value)
+		}
+		return date, nil
+	}
+	return modTime, nil
+}
+
+// Returns the date for the date tokens of a permalink pattern. Patterns that
+// use :year, :month or :day need a date in the front matter, since the
+// modification time of a file changes between checkouts.
+func permalinkDate(pattern string, metaData map[string]interface{}) (time.Time, error) {
+	usesDate := false
+	for _, token := range permalinkTokenPattern.FindAllString(pattern, -1) {
+		if token == ":year" || token == ":month" || token == ":day" {
+			usesDate = true
+		}
+	}
+	if !usesDate {
+		return time.Time{}, nil
+	}
+
+	date, err := pageDate(metaData, time.Time{})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if date.IsZero() {
+		return time.Time{}, fmt.Errorf("permalink pattern %q uses the date, add %s to the front matter", pattern, strings.Join(permalinkDateKeys, " or "))
+	}
+	return date, nil
+}
+
+// Check if a page has a date in its front matter
+func hasDate(page FileInfo) bool {
+	for _, key := range permalinkDateKeys {
+		if metaString(page.MetaData, key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Clean up a permalink so it starts with a slash and ends with a slash or an
+// output extension. Permalinks without either get .html with uglyURLs, or a slash.
+func normalizePermalink(permalink string) string {
+	hasTrailingSlash := strings.HasSuffix(permalink, "/")
+	permalink = path.Clean("/" + permalink)
+	if permalink == "/" {
+		return permalink
+	}
+	if hasTrailingSlash {
+		return permalink + "/"
+	}
+	if permalinkExtensions[strings.ToLower(path.Ext(permalink))] {
+		return permalink
+	}
+	if config.UglyURLs {
+		return permalink + ".html"
+	}
+	return permalink + "/"
+}
+
+// Returns the full URL of a permalink using the site URL from the config
+// The permalink is returned as it is when the site URL isn't set
+func absoluteURL(permalink string) string {
+	if config.URL == "" {
+		return permalink
+	}
+	// The site URL may be configured without a scheme (e.g. "mysite.com")
+	siteURL := config.URL
+	if !strings.Contains(siteURL, "://") {
+		siteURL = "https://" + siteURL
+	}
+	return strings.TrimSuffix(siteURL, "/") + permalink
+}
+
+// Convert text into a lowercase, dash separated slug for URLs
+func slugify(text string) string {
+	return strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(text), "-"), "-")
+}
+
+// Returns the files in the list other than the given file
+func otherFiles(files []string, file string) []string {
+	var others []string
+	for _, other := range files {
+		if other != file {
+			others = append(others, other)
+		}
+	}
+	return others
+}
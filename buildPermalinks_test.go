@@ -0,0 +1,93 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuilder_Permalink(t *testing.T) {
+	restoreConfig(t)
+	config.Permalinks = map[string]string{
+		"post":    "/:year/:month/:slug/",
+		"project": "/work/:title",
+	}
+	config.UglyURLs = true
+
+	b := Builder{}
+
+	tests := []struct {
+		name     string
+		dir      string
+		section  string
+		fileName string
+		metaData map[string]interface{}
+		want     string
+	}{
+		{"no pattern", "", "", "about", nil, "/about.html"},
+		{"no pattern with slug", "page", "page", "about", map[string]interface{}{"slug": "about-us"}, "/page/about-us.html"},
+		{"pattern with date", "post", "post", "hello", map[string]interface{}{"publish_date": "2024-01-15"}, "/2024/01/hello/"},
+		{"pattern with slug", "post", "post", "hello", map[string]interface{}{"date": "2024-02-01", "slug": "hi"}, "/2024/02/hi/"},
+		{"pattern with a time", "post", "post", "hello", map[string]interface{}{"date": time.Date(2023, 7, 4, 0, 0, 0, 0, time.UTC)}, "/2023/07/hello/"},
+		{"pattern with title", "project", "project", "one", map[string]interface{}{"title": "My First Project!"}, "/work/my-first-project.html"},
+		{"index file", "post", "post", "index", nil, "/post/index.html"},
+		{"url override", "post", "post", "hello", map[string]interface{}{"url": "/old/hello-world/"}, "/old/hello-world/"},
+		{"url with extension", "", "", "feed", map[string]interface{}{"url": "/feed.xml"}, "/feed.xml"},
+		// A dot in a file name or slug isn't an extension
+		{"dotted file name", "", "", "go-1.21", nil, "/go-1.21.html"},
+		{"dotted slug", "page", "page", "release", map[string]interface{}{"slug": "v1.2-release"}, "/page/v1.2-release.html"},
+	}
+	for _, tt := range tests {
+		got, err := b.permalink("", tt.dir, tt.section, tt.fileName, tt.metaData)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: permalink mismatch. Got: %q, Want: %q", tt.name, got, tt.want)
+		}
+	}
+
+	// Without uglyURLs pages are written to their own directory
+	config.UglyURLs = false
+	prettyTests := []struct {
+		dir      string
+		section  string
+		fileName string
+		want     string
+	}{
+		{"", "", "about", "/about/"},
+		{"", "", "index", "/"},
+		{"docs", "docs", "index", "/docs/"},
+		{"docs/guide", "docs", "setup", "/docs/guide/setup/"},
+		{"project", "project", "one", "/work/one/"},
+		{"docs", "docs", "go-1.21", "/docs/go-1.21/"},
+	}
+	for _, tt := range prettyTests {
+		got, err := b.permalink("", tt.dir, tt.section, tt.fileName, map[string]interface{}{})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.fileName, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Pretty permalink mismatch for %s/%s. Got: %q, Want: %q", tt.dir, tt.fileName, got, tt.want)
+		}
+	}
+
+	// Unknown tokens and invalid dates are errors
+	config.Permalinks["post"] = "/:author/:slug/"
+	if _, err := b.permalink("", "post", "post", "hello", nil); err == nil {
+		t.Error("Expected an error for an unknown token")
+	}
+	config.Permalinks["post"] = "/:year/:slug/"
+	if _, err := b.permalink("", "post", "post", "hello", map[string]interface{}{"date": "soon"}); err == nil {
+		t.Error("Expected an error for an invalid date")
+	}
+
+	// Dated patterns need a date in the front matter, so URLs don't change with the file's modification time
+	for _, metaData := range []map[string]interface{}{nil, {"publish_date": nil}, {"date": ""}} {
+		if _, err := b.permalink("", "post", "post", "hello", metaData); err == nil || !strings.Contains(err.Error(), "uses the date") {
+			t.Errorf("Expected an error for a dated pattern without a date in %v. Got: %v", metaData, err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// RelatedConfig controls the related pages listed on each page as .Related
+type RelatedConfig struct {
+	// Limit is the number of related pages for each page - defaults to 5
+	Limit int `yaml:"limit"`
+	// Weights sets how much each shared front matter value counts towards
+	// the score of a related page, keyed by the front matter key (e.g. tags).
+	// The title key counts the words shared by the titles.
+	// Defaults to tags: 3, categories: 2, title: 1 - use 0 to leave a key out
+	Weights map[string]int `yaml:"weights"`
+}
+
+// The key in the related weights that compares the words in the titles
+const relatedTitleKey = "title"
+
+// **********  Private Related Methods  **********
+
+// Work out the related pages of every page from the values they share, e.g.
+// tags or words in their titles. Pages are related to pages in their own
+// language, sorted by score, then newest first, then by permalink so every
+// build lists them in the same order.
+func (b *Builder) buildRelated(dirsMap map[string]DirectoryInfo) {
+	if config.Related.Limit <= 0 || len(config.Related.Weights) == 0 {
+		return
+	}
+
+	// Index the pages by each of their values, e.g. "tags:go"
+	pages := sortedFiles(dirsMap)
+	index := make(map[string][]int)
+	values := make([][]string, len(pages))
+	for i, page := range pages {
+		if !isRelatable(page) {
+			continue
+		}
+		values[i] = relatedValues(page)
+		for _, value := range values[i] {
+			index[value] = append(index[value], i)
+		}
+	}
+
+	related := make(map[string][]FileInfo)
+	for i, page := range pages {
+		scores := make(map[int]int)
+		for _, value := range values[i] {
+			weight := config.Related.Weights[strings.SplitN(value, ":", 2)[0]]
+			for _, other := range index[value] {
+				if other != i && pages[other].Language == page.Language {
+					scores[other] += weight
+				}
+			}
+		}
+
+		candidates := make([]int, 0, len(scores))
+		for other, score := range scores {
+			if score > 0 {
+				candidates = append(candidates, other)
+			}
+		}
+		sort.Slice(candidates, func(a, b int) bool {
+			pageA, pageB := pages[candidates[a]], pages[candidates[b]]
+			if scores[candidates[a]] != scores[candidates[b]] {
+				return scores[candidates[a]] > scores[candidates[b]]
+			}
+			if !pageA.Date.Equal(pageB.Date) {
+				return pageA.Date.After(pageB.Date)
+			}
+			return pageA.OutputPath < pageB.OutputPath
+		})
+		if len(candidates) > config.Related.Limit {
+			candidates = candidates[:config.Related.Limit]
+		}
+
+		for _, other := range candidates {
+			related[page.Path] = append(related[page.Path], pages[other])
+		}
+	}
+
+	for dirKey, dirInfo := range dirsMap {
+		for i, file := range dirInfo.Files {
+			dirInfo.Files[i].Related = related[file.Path]
+		}
+		dirsMap[dirKey] = dirInfo
+	}
+}
+
+// Returns the values of a page that can be shared with related pages,
+// prefixed with their key, e.g. "tags:go" or "title:search"
+func relatedValues(page FileInfo) []string {
+	var values []string
+	for _, key := range sortedKeys(config.Related.Weights) {
+		if config.Related.Weights[key] <= 0 {
+			continue
+		}
+		if key == relatedTitleKey {
+			for _, term := range searchTerms(metaString(page.MetaData, "title")) {
+				values = append(values, key+":"+term)
+			}
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, value := range metaStrings(page.MetaData, key) {
+			value = strings.ToLower(strings.TrimSpace(value))
+			if value != "" && !seen[value] {
+				seen[value] = true
+				values = append(values, key+":"+value)
+			}
+		}
+	}
+	return values
+}
+
+// Check if a page can be listed as a related page.
+// Index pages and pages with `index: false` are left out.
+func isRelatable(page FileInfo) bool {
+	if index, exists := page.MetaData["index"].(bool); exists && !index {
+		return false
+	}
+	return page.Name != "index"
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuilder_BuildRelated(t *testing.T) {
+	restoreConfig(t)
+	config.Related = RelatedConfig{Limit: 2, Weights: map[string]int{"tags": 3, "categories": 2, "title": 1}}
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	page := func(name string, date time.Time, meta map[string]interface{}) FileInfo {
+		return FileInfo{Name: name, Path: "post/" + name + ".md", OutputPath: "/post/" + name + "/", Date: date, MetaData: meta}
+	}
+	dirsMap := map[string]DirectoryInfo{
+		"content/post": {Files: []FileInfo{
+			page("go-search", day(1), map[string]interface{}{"title": "Searching with Go", "tags": []interface{}{"go", "search"}}),
+			page("go-tips", day(2), map[string]interface{}{"title": "Go tips", "tags": []interface{}{"Go"}}),
+			page("go-news", day(3), map[string]interface{}{"title": "News", "tags": "go"}),
+			page("search", day(4), map[string]interface{}{"title": "Search engines", "categories": []interface{}{"web"}}),
+			page("hidden", day(5), map[string]interface{}{"title": "Go search", "tags": []interface{}{"go", "search"}, "index": false}),
+			page("index", day(6), map[string]interface{}{"tags": []interface{}{"go", "search"}}),
+		}},
+	}
+
+	b := Builder{}
+	b.buildRelated(dirsMap)
+
+	related := func(i int) []string {
+		var names []string
+		for _, file := range dirsMap["content/post"].Files[i].Related {
+			names = append(names, file.Name)
+		}
+		return names
+	}
+
+	// go-tips shares the go tag and a title word (3 + 1), go-news only the tag
+	// (3), and search only a title word (1). Hidden and index pages are left out.
+	if got := related(0); len(got) != 2 || got[0] != "go-tips" || got[1] != "go-news" {
+		t.Errorf("Related pages mismatch. Got: %v, Want: [go-tips go-news]", got)
+	}
+	// Pages with the same score are sorted newest first
+	if got := related(2); len(got) != 2 || got[0] != "go-tips" || got[1] != "go-search" {
+		t.Errorf("Related pages mismatch. Got: %v, Want: [go-tips go-search]", got)
+	}
+	if got := related(3); len(got) != 1 || got[0] != "go-search" {
+		t.Errorf("Related pages mismatch. Got: %v, Want: [go-search]", got)
+	}
+}
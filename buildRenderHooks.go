@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// RenderHooks overrides the default markdown rendering for links, images and
+// headings. Each element is rendered with template/_hooks/<element>.tmpl when
+// it exists, otherwise with the built in behavior enabled in the config.
+type RenderHooks struct {
+	builder  *Builder
+	page     FileInfo          // The file being rendered
+	renderer renderer.Renderer // The renderer used to render the children of a node
+}
+
+// LinkHook holds the data passed into the _hooks/link.tmpl template
+type LinkHook struct {
+	Destination string        // The URL of the link
+	Title       string        // The title of the link
+	Text        template.HTML // The rendered content of the link
+	IsExternal  bool          // Whether the link points to another site
+}
+
+// ImageHook holds the data passed into the _hooks/image.tmpl template
+type ImageHook struct {
+	Destination string // The URL of the image
+	Title       string // The title of the image
+	Alt         string // The alt text of the image
+	IsBlock     bool   // Whether the image sits on its own line
+}
+
+// HeadingHook holds the data passed into the _hooks/heading.tmpl template
+type HeadingHook struct {
+	Level int           // The heading level (1-6)
+	ID    string        // The generated anchor ID
+	Text  template.HTML // The rendered content of the heading
+}
+
+// The directory (relative to the template directory) that holds render hooks
+const hooksDir = "_hooks"
+
+// The priority of the render hooks, lower than the default HTML renderer so
+// the hooks replace its functions
+const renderHooksPriority = 100
+
+// **********  Public RenderHooks Methods  **********
+
+// RegisterFuncs registers the render hook functions with goldmark
+func (r *RenderHooks) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindLink, r.renderLink)
+	reg.Register(ast.KindImage, r.renderImage)
+	reg.Register(ast.KindHeading, r.renderHeading)
+	reg.Register(ast.KindParagraph, r.renderParagraph)
+}
+
+// **********  Private RenderHooks Methods  **********
+
+// Render a link, resolving links to content and opening external links in a new tab
+func (r *RenderHooks) renderLink(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.Link)
+
+	// Point links to markdown files at the output path of the file
+	n.Destination = []byte(r.builder.resolveLink(r.page, string(n.Destination)))
+
+	text, err := r.renderChildren(source, n)
+	if err != nil {
+		return ast.WalkStop, err
+	}
+	data := LinkHook{
+		Destination: string(n.Destination),
+		Title:       string(n.Title),
+		Text:        text,
+		IsExternal:  isExternalURL(string(n.Destination)),
+	}
+
+	if r.hasHook("link") {
+		return ast.WalkSkipChildren, r.executeHook(w, "link", data)
+	}
+
+	_, _ = w.WriteString(`<a href="`)
+	if !html.IsDangerousURL(n.Destination) {
+		_, _ = w.Write(util.EscapeHTML(util.URLEscape(n.Destination, true)))
+	}
+	_ = w.WriteByte('"')
+	if n.Title != nil {
+		_, _ = w.WriteString(` title="`)
+		_, _ = w.Write(util.EscapeHTML(n.Title))
+		_ = w.WriteByte('"')
+	}
+	if data.IsExternal && config.Markdown.ExternalLinks {
+		_, _ = w.WriteString(` rel="noopener" target="_blank"`)
+	}
+	_ = w.WriteByte('>')
+	_, _ = w.WriteString(string(text))
+	_, _ = w.WriteString("</a>")
+
+	return ast.WalkSkipChildren, nil
+}
+
+// Render an image, wrapping images that sit on their own line in a figure
+func (r *RenderHooks) renderImage(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.Image)
+
+	data := ImageHook{
+		Destination: string(n.Destination),
+		Title:       string(n.Title),
+		Alt:         string(n.Text(source)),
+		IsBlock:     isBlockImage(n),
+	}
+
+	if r.hasHook("image") {
+		return ast.WalkSkipChildren, r.executeHook(w, "image", data)
+	}
+
+	isFigure := data.IsBlock && config.Markdown.ImageFigures
+	if isFigure {
+		_, _ = w.WriteString("<figure>\n")
+	}
+	_, _ = w.WriteString(`<img src="`)
+	if !html.IsDangerousURL(n.Destination) {
+		_, _ = w.Write(util.EscapeHTML(util.URLEscape(n.Destination, true)))
+	}
+	_, _ = w.WriteString(`" alt="`)
+	_, _ = w.Write(util.EscapeHTML([]byte(data.Alt)))
+	_ = w.WriteByte('"')
+	if n.Title != nil {
+		_, _ = w.WriteString(` title="`)
+		_, _ = w.Write(util.EscapeHTML(n.Title))
+		_ = w.WriteByte('"')
+	}
+	_ = w.WriteByte('>')
+	if isFigure {
+		// Use the title as the caption, falling back to the alt text
+		caption := data.Title
+		if caption == "" {
+			caption = data.Alt
+		}
+		if caption != "" {
+			_, _ = w.WriteString("\n<figcaption>")
+			_, _ = w.Write(util.EscapeHTML([]byte(caption)))
+			_, _ = w.WriteString("</figcaption>")
+		}
+		_, _ = w.WriteString("\n</figure>")
+	}
+
+	return ast.WalkSkipChildren, nil
+}
+
+// Render a heading with an anchor link to its ID
+func (r *RenderHooks) renderHeading(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.Heading)
+
+	text, err := r.renderChildren(source, n)
+	if err != nil {
+		return ast.WalkStop, err
+	}
+	data := HeadingHook{
+		Level: n.Level,
+		Text:  text,
+	}
+	if id, exists := n.AttributeString("id"); exists {
+		data.ID = fmt.Sprintf("%s", id)
+	}
+
+	if r.hasHook("heading") {
+		return ast.WalkSkipChildren, r.executeHook(w, "heading", data)
+	}
+
+	fmt.Fprintf(w, "<h%d", n.Level)
+	if n.Attributes() != nil {
+		html.RenderAttributes(w, n, html.HeadingAttributeFilter)
+	}
+	_ = w.WriteByte('>')
+	_, _ = w.WriteString(string(text))
+	if data.ID != "" && config.Markdown.HeadingAnchors {
+		fmt.Fprintf(w, ` <a class="anchor" href="#%s" aria-label="Link to this section">#</a>`, data.ID)
+	}
+	fmt.Fprintf(w, "</h%d>\n", n.Level)
+
+	return ast.WalkSkipChildren, nil
+}
+
+// Render a paragraph, leaving out the <p> tags around images that are
+// rendered as a figure since a figure can't sit inside a paragraph
+func (r *RenderHooks) renderParagraph(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	image, isImage := node.FirstChild().(*ast.Image)
+	if isImage && isBlockImage(image) && (config.Markdown.ImageFigures || r.hasHook("image")) {
+		if !entering {
+			_ = w.WriteByte('\n')
+		}
+		return ast.WalkContinue, nil
+	}
+
+	if entering {
+		if node.Attributes() != nil {
+			_, _ = w.WriteString("<p")
+			html.RenderAttributes(w, node, html.ParagraphAttributeFilter)
+			_ = w.WriteByte('>')
+		} else {
+			_, _ = w.WriteString("<p>")
+		}
+	} else {
+		_, _ = w.WriteString("</p>\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+// Render the children of a node to HTML
+func (r *RenderHooks) renderChildren(source []byte, node ast.Node) (template.HTML, error) {
+	var buf bytes.Buffer
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if err := r.renderer.Render(&buf, source, child); err != nil {
+			return "", err
+		}
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// Check if the site has a template for the given hook
+func (r *RenderHooks) hasHook(name string) bool {
+	return r.builder.contentTemplates != nil &&
+		r.builder.contentTemplates.Lookup(hooksDir+"/"+name+".tmpl") != nil
+}
+
+// Execute the template for the given hook
+func (r *RenderHooks) executeHook(w util.BufWriter, name string, data interface{}) error {
+	templateName := hooksDir + "/" + name + ".tmpl"
+	if err := r.builder.contentTemplates.ExecuteTemplate(w, templateName, data); err != nil {
+		return fmt.Errorf("error rendering %s: %w", templateName, err)
+	}
+	return nil
+}
+
+// Check if an image is the only content of its paragraph
+func isBlockImage(image *ast.Image) bool {
+	parent := image.Parent()
+	return parent != nil && parent.Kind() == ast.KindParagraph && parent.ChildCount() == 1
+}
+
+// Check if a URL points to a different site than the configured site URL
+func isExternalURL(destination string) bool {
+	parsed, err := url.Parse(destination)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	if parsed.Scheme != "" && parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return false
+	}
+
+	// The site URL may be configured without a scheme (e.g. "mysite.com")
+	siteURL := config.URL
+	if !strings.Contains(siteURL, "://") {
+		siteURL = "https://" + siteURL
+	}
+	site, err := url.Parse(siteURL)
+	if err != nil || site.Host == "" {
+		return true
+	}
+
+	return strings.TrimPrefix(parsed.Hostname(), "www.") != strings.TrimPrefix(site.Hostname(), "www.")
+}
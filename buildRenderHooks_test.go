@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Render markdown through the builder's markdown renderer
+func renderMarkdown(t *testing.T, b *Builder, source string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := b.markdown(FileInfo{Path: "post/hello.md"}).Convert([]byte(source), &buf); err != nil {
+		t.Fatalf("Convert returned an error: %v", err)
+	}
+	return buf.String()
+}
+
+func TestRenderHooks_Defaults(t *testing.T) {
+	restoreConfig(t)
+	config.URL = "mysite.com"
+
+	enabled := MarkdownConfig{ExternalLinks: true, ImageFigures: true, HeadingAnchors: true}
+	tests := []struct {
+		name     string
+		markdown MarkdownConfig
+		source   string
+		want     string
+	}{
+		{"internal link", enabled, "[a](/about/)", "<p><a href=\"/about/\">a</a></p>\n"},
+		{"external link", enabled, "[a](https://example.com \"Ex\")",
+			"<p><a href=\"https://example.com\" title=\"Ex\" rel=\"noopener\" target=\"_blank\">a</a></p>\n"},
+		{"external links off", MarkdownConfig{}, "[a](https://example.com)", "<p><a href=\"https://example.com\">a</a></p>\n"},
+		{"dangerous link", enabled, "[a](javascript:alert(1))", "<p><a href=\"\">a</a></p>\n"},
+		{"block image", enabled, "![Cat](cat.png \"A cat\")",
+			"<figure>\n<img src=\"cat.png\" alt=\"Cat\" title=\"A cat\">\n<figcaption>A cat</figcaption>\n</figure>\n"},
+		{"block image without title", enabled, "![Cat](cat.png)",
+			"<figure>\n<img src=\"cat.png\" alt=\"Cat\">\n<figcaption>Cat</figcaption>\n</figure>\n"},
+		{"inline image", enabled, "A ![cat](cat.png) here", "<p>A <img src=\"cat.png\" alt=\"cat\"> here</p>\n"},
+		{"image figures off", MarkdownConfig{}, "![Cat](cat.png)", "<p><img src=\"cat.png\" alt=\"Cat\"></p>\n"},
+		{"heading", enabled, "## Hello *world*",
+			"<h2 id=\"hello-world\">Hello <em>world</em> <a class=\"anchor\" href=\"#hello-world\" aria-label=\"Link to this section\">#</a></h2>\n"},
+		{"heading anchors off", MarkdownConfig{}, "## Hello", "<h2 id=\"hello\">Hello</h2>\n"},
+	}
+	for _, tt := range tests {
+		config.Markdown = tt.markdown
+		b := Builder{}
+		if got := renderMarkdown(t, &b, tt.source); got != tt.want {
+			t.Errorf("%s: HTML mismatch.\nGot:  %q\nWant: %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRenderHooks_Templates(t *testing.T) {
+	restoreConfig(t)
+	config.URL = "mysite.com"
+	config.Markdown = MarkdownConfig{}
+
+	b := templateBuilder(t, map[string]string{
+		"fullpage.tmpl":       `{{ .Content }}`,
+		"_hooks/link.tmpl":    `<a href="{{ .Destination }}"{{ if .IsExternal }} class="ext"{{ end }}>{{ .Text }}</a>`,
+		"_hooks/image.tmpl":   `<img src="{{ .Destination }}" data-block="{{ .IsBlock }}">`,
+		"_hooks/heading.tmpl": `<h{{ .Level }} id="{{ .ID }}">{{ .Text }}</h{{ .Level }}>`,
+	})
+
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"link", "[a *b*](https://example.com)", "<p><a href=\"https://example.com\" class=\"ext\">a <em>b</em></a></p>\n"},
+		{"same site link", "[a](https://www.mysite.com/x/)", "<p><a href=\"https://www.mysite.com/x/\">a</a></p>\n"},
+		// A block image isn't wrapped in a paragraph when a hook renders it
+		{"block image", "![Cat](cat.png)", "<img src=\"cat.png\" data-block=\"true\">\n"},
+		{"inline image", "A ![cat](cat.png)", "<p>A <img src=\"cat.png\" data-block=\"false\"></p>\n"},
+		{"heading", "# Title", "<h1 id=\"title\">Title</h1>"},
+	}
+	for _, tt := range tests {
+		if got := renderMarkdown(t, &b, tt.source); got != tt.want {
+			t.Errorf("%s: HTML mismatch.\nGot:  %q\nWant: %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsExternalURL(t *testing.T) {
+	restoreConfig(t)
+
+	tests := []struct {
+		siteURL     string
+		destination string
+		want        bool
+	}{
+		{"mysite.com", "https://example.com/a", true},
+		{"mysite.com", "http://mysite.com/a", false},
+		{"mysite.com", "https://www.mysite.com/a", false},
+		{"https://www.mysite.com", "https://mysite.com/a", false},
+		{"mysite.com", "/about/", false},
+		{"mysite.com", "about.md", false},
+		{"mysite.com", "mailto:me@example.com", false},
+		{"mysite.com", "ftp://example.com/file", false},
+		{"", "https://example.com", true},
+	}
+	for _, tt := range tests {
+		config.URL = tt.siteURL
+		if got := isExternalURL(tt.destination); got != tt.want {
+			t.Errorf("isExternalURL(%q) with site %q = %v, want %v", tt.destination, tt.siteURL, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SEOConfig holds the site wide fallbacks for the metadata of each page
+type SEOConfig struct {
+	// Description is used for pages without a description or text
+	Description string `yaml:"description"`
+	// Image is the image shared on social media for pages without an image
+	Image string `yaml:"image"`
+	// Twitter is the site's Twitter/X handle, e.g. @repose
+	Twitter string `yaml:"twitter"`
+}
+
+// SEO holds the metadata for the head of a page, used by the seo partial
+// Each value falls back to the site config when the front matter doesn't set it
+type SEO struct {
+	Title       string                 // The title of the page
+	Description string                 // The description from the front matter, text or config
+	Canonical   string                 // The absolute URL of the page, empty when the site URL isn't set
+	Image       string                 // The absolute URL of the image to share
+	Type        string                 // The OpenGraph type, article or website
+	Published   string                 // The date the article was published (RFC 3339)
+	Authors     []string               // The authors of the page
+	SiteName    string                 // The name of the site
+	Locale      string                 // The language of the page, e.g. en_US
+	Twitter     string                 // The site's Twitter/X handle
+	TwitterCard string                 // The Twitter card, summary or summary_large_image
+	NoIndex     bool                   // Set for pages with index: false
+	JSONLD      map[string]interface{} // The JSON-LD structured data
+}
+
+// The length of descriptions taken from the text of a page
+const seoDescriptionLength = 160
+
+// **********  Public PageData Methods  **********
+
+// SEO returns the metadata for the head of the page, used in templates
+// as {{ partial "seo" . }} or {{ .SEO.Description }}
+func (p PageData) SEO() SEO {
+	page := p.Page
+	seo := SEO{
+		Title:       p.Title,
+		Description: pageSummary(page, seoDescriptionLength),
+		Image:       seoImage(page.OutputPath, metaString(page.MetaData, "image")),
+		Type:        "website",
+		Authors:     metaStrings(page.MetaData, "author"),
+		SiteName:    p.Site.Name,
+		Locale:      strings.ReplaceAll(page.Language, "-", "_"),
+		Twitter:     config.SEO.Twitter,
+	}
+	if len(page.Authors) > 0 {
+		seo.Authors = nil
+		for _, author := range page.Authors {
+			seo.Authors = append(seo.Authors, author.Name)
+		}
+	}
+	// Canonical URLs must be absolute, so they are left out until the site URL is set
+	if config.URL != "" {
+		seo.Canonical = absoluteURL(page.OutputPath)
+	}
+	if canonical := metaString(page.MetaData, "canonical"); canonical != "" {
+		seo.Canonical = canonical
+	}
+	if seo.Description == "" {
+		seo.Description = config.SEO.Description
+	}
+	if seo.Image == "" && config.SEO.Image != "" {
+		seo.Image = seoImage("/", config.SEO.Image)
+	}
+	if seo.SiteName == "" {
+		seo.SiteName = config.Sitename
+	}
+	if len(seo.Authors) == 0 && config.Author != "" {
+		seo.Authors = []string{config.Author}
+	}
+	if index, exists := page.MetaData["index"].(bool); exists && !index {
+		seo.NoIndex = true
+	}
+
+	seo.TwitterCard = "summary"
+	if seo.Image != "" {
+		seo.TwitterCard = "summary_large_image"
+	}
+
+	// Pages with a date in their front matter are articles
+	if isArticle(page) {
+		seo.Type = "article"
+		seo.Published = page.Date.Format(time.RFC3339)
+	}
+	home := p.Site.Home
+	if home == "" {
+		home = listPermalink("")
+	}
+	seo.JSONLD = seo.structuredData(page.OutputPath == home)
+
+	return seo
+}
+
+// **********  Private SEO Methods  **********
+
+// Build the JSON-LD for an Article, the WebSite for the home page or a
+// WebPage for other pages
+func (s SEO) structuredData(isHome bool) map[string]interface{} {
+	if s.Type != "article" {
+		data := map[string]interface{}{
+			"@context": "https://schema.org",
+			"@type":    "WebPage",
+			"name":     s.Title,
+		}
+		if isHome {
+			data["@type"] = "WebSite"
+			data["name"] = s.SiteName
+		}
+		if s.Canonical != "" {
+			data["url"] = s.Canonical
+		}
+		if s.Description != "" {
+			data["description"] = s.Description
+		}
+		return data
+	}
+
+	authors := make([]map[string]interface{}, 0, len(s.Authors))
+	for _, author := range s.Authors {
+		authors = append(authors, map[string]interface{}{"@type": "Person", "name": author})
+	}
+	data := map[string]interface{}{
+		"@context":      "https://schema.org",
+		"@type":         "Article",
+		"headline":      s.Title,
+		"datePublished": s.Published,
+		"author":        authors,
+		"publisher":     map[string]interface{}{"@type": "Organization", "name": s.SiteName},
+	}
+	if s.Description != "" {
+		data["description"] = s.Description
+	}
+	if s.Canonical != "" {
+		data["mainEntityOfPage"] = s.Canonical
+	}
+	if s.Image != "" {
+		data["image"] = s.Image
+	}
+	return data
+}
+
+// Check if a page is an article, from a date in its front matter
+func isArticle(page FileInfo) bool {
+	return page.Name != "index" && hasDate(page)
+}
+
+// Returns the absolute URL of an image, resolving relative paths against the page.
+// Social media need absolute image URLs, so relative images are left out until
+// the site URL is set.
+func seoImage(permalink string, image string) string {
+	if image == "" || strings.Contains(image, "://") {
+		return image
+	}
+	if config.URL == "" {
+		return ""
+	}
+	base, err := url.Parse(permalink)
+	if err != nil {
+		return image
+	}
+	reference, err := url.Parse(image)
+	if err != nil {
+		return image
+	}
+	return absoluteURL(base.ResolveReference(reference).String())
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPageData_SEO(t *testing.T) {
+	restoreConfig(t)
+	config.URL = "https://example.com"
+	config.Author = "Creator"
+	config.SEO = SEOConfig{Description: "A site about things", Image: "/img/share.png", Twitter: "@example"}
+
+	article := PageData{
+		Title: "Hello",
+		Page: FileInfo{
+			Name:       "hello",
+			OutputPath: "/post/hello/",
+			Language:   "en",
+			Date:       time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			MetaData: map[string]interface{}{
+				"description": "Saying hello",
+				"date":        "2024-03-01",
+				"image":       "cover.jpg",
+				"author":      "Ann",
+			},
+		},
+		Site: Site{Name: "Example", Home: "/"},
+	}
+
+	seo := article.SEO()
+	if seo.Type != "article" || seo.Published != "2024-03-01T00:00:00Z" {
+		t.Errorf("Expected an article published on 2024-03-01. Got: %s %s", seo.Type, seo.Published)
+	}
+	if seo.Canonical != "https://example.com/post/hello/" || seo.Image != "https://example.com/post/hello/cover.jpg" {
+		t.Errorf("URL mismatch. Got: %s %s", seo.Canonical, seo.Image)
+	}
+	if seo.Description != "Saying hello" || len(seo.Authors) != 1 || seo.Authors[0] != "Ann" {
+		t.Errorf("Front matter mismatch. Got: %q %v", seo.Description, seo.Authors)
+	}
+	if seo.JSONLD["@type"] != "Article" || seo.JSONLD["headline"] != "Hello" {
+		t.Errorf("Expected Article structured data. Got: %v", seo.JSONLD)
+	}
+
+	// Pages without front matter fall back to the site config
+	list := PageData{Title: "All posts", Page: FileInfo{OutputPath: "/post/"}, Site: Site{Name: "Example", Home: "/"}}
+	seo = list.SEO()
+	if seo.Type != "website" || seo.Description != "A site about things" || seo.Image != "https://example.com/img/share.png" {
+		t.Errorf("Expected the config fallbacks. Got: %s %q %s", seo.Type, seo.Description, seo.Image)
+	}
+	if seo.Authors[0] != "Creator" || seo.TwitterCard != "summary_large_image" {
+		t.Errorf("Expected the config author and a large card. Got: %v %s", seo.Authors, seo.TwitterCard)
+	}
+	if seo.JSONLD["@type"] != "WebPage" || seo.JSONLD["name"] != "All posts" || seo.JSONLD["url"] != "https://example.com/post/" {
+		t.Errorf("Expected WebPage structured data. Got: %v", seo.JSONLD)
+	}
+
+	// Only the home page is the WebSite
+	home := PageData{Title: "Home", Page: FileInfo{Name: "index", OutputPath: "/"}, Site: Site{Name: "Example", Home: "/"}}
+	seo = home.SEO()
+	if seo.JSONLD["@type"] != "WebSite" || seo.JSONLD["name"] != "Example" || seo.JSONLD["url"] != "https://example.com/" {
+		t.Errorf("Expected WebSite structured data. Got: %v", seo.JSONLD)
+	}
+
+	// Without a site URL there is no absolute URL, so the canonical, og:url and image tags are left out
+	config.URL = ""
+	seo = article.SEO()
+	if _, hasURL := seo.JSONLD["mainEntityOfPage"]; seo.Canonical != "" || hasURL {
+		t.Errorf("Expected no canonical URL without a site URL. Got: %q %v", seo.Canonical, seo.JSONLD)
+	}
+	if _, hasImage := seo.JSONLD["image"]; seo.Image != "" || hasImage {
+		t.Errorf("Expected no image without a site URL. Got: %q %v", seo.Image, seo.JSONLD)
+	}
+	b := templateBuilder(t, map[string]string{"fullpage.tmpl": `{{ .Content }}`, "head.tmpl": `{{ partial "seo" . }}`})
+	output, err := b.renderPage("head.tmpl", article, article)
+	if err != nil {
+		t.Fatalf("renderPage returned an error: %v", err)
+	}
+	if strings.Contains(output, "canonical") || strings.Contains(output, "og:url") || strings.Contains(output, "image") {
+		t.Errorf("Expected no canonical, og:url or image tags. Got:\n%s", output)
+	}
+
+	// Pages without a description don't get empty description tags
+	config.SEO.Description = ""
+	plain := PageData{Title: "Plain", Page: FileInfo{Name: "plain", OutputPath: "/plain/"}}
+	output, err = b.renderPage("head.tmpl", plain, plain)
+	if err != nil {
+		t.Fatalf("renderPage returned an error: %v", err)
+	}
+	if strings.Contains(output, "description") {
+		t.Errorf("Expected no description tags. Got:\n%s", output)
+	}
+}
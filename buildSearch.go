@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SearchConfig controls the search index written to search.json
+type SearchConfig struct {
+	// Enabled writes the search index - defaults to true
+	Enabled bool `yaml:"enabled"`
+	// Fields lists the fields of each page in the index. Defaults to title, url,
+	// summary, tags, section and content. Other names are read from the front matter
+	Fields []string `yaml:"fields"`
+	// Tokenize replaces the content with its stemmed, unique words to keep the
+	// index small. The search script stems the query the same way
+	Tokenize bool `yaml:"tokenize"`
+	// SummaryLength is the length of summaries taken from the content when a
+	// page has no description - defaults to 160
+	SummaryLength int `yaml:"summaryLength"`
+}
+
+// The file the search index is written to, in the output directory
+const searchIndexFile = "search.json"
+
+// The fields in the search index by default
+var defaultSearchFields = []string{"title", "url", "summary", "tags", "section", "content"}
+
+// Patterns used to strip the markup from the page content
+var (
+	headingAnchorPattern = regexp.MustCompile(`(?is)<a class="anchor"[^>]*>.*?</a>`)
+	htmlTagPattern       = regexp.MustCompile(`(?s)<[^>]*>`)
+	asciiWordPattern     = regexp.MustCompile(`^[a-z0-9]+$`)
+)
+
+// Common English words left out of the tokenized index
+var searchStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"but": true, "by": true, "for": true, "if": true, "in": true, "into": true, "is": true,
+	"it": true, "no": true, "not": true, "of": true, "on": true, "or": true, "such": true,
+	"that": true, "the": true, "their": true, "then": true, "there": true, "these": true,
+	"they": true, "this": true, "to": true, "was": true, "will": true, "with": true,
+}
+
+// The suffixes removed by the stemmer, in the order they are tried.
+// search.js uses the same rules, so keep them in sync.
+var stemSuffixes = []struct {
+	suffix  string
+	replace string
+}{
+	{"sses", "ss"},
+	{"ies", "y"},
+	{"ied", "y"},
+	{"ingly", ""},
+	{"edly", ""},
+	{"ing", ""},
+	{"ed", ""},
+	{"ly", ""},
+	{"ss", "ss"},
+	{"us", "us"},
+	{"is", "is"},
+	{"s", ""},
+}
+
+// **********  Private Search Methods  **********
+
+// Write the search index with a record for every page that can be indexed.
+// Pages are left out with `index: false` in their front matter.
+// Each language has its own index, e.g. /search.json and /es/search.json
+func (b *Builder) buildSearchIndex(dirsMap map[string]DirectoryInfo) error {
+	if !config.Search.Enabled {
+		return nil
+	}
+	logger.Info("Building search index")
+
+	fields := config.Search.Fields
+	if len(fields) == 0 {
+		fields = defaultSearchFields
+	}
+
+	records := make(map[string][]map[string]interface{})
+	for _, lang := range languageCodes() {
+		records[lang] = []map[string]interface{}{}
+	}
+	for _, file := range sortedFiles(dirsMap) {
+		if index, exists := file.MetaData["index"].(bool); exists && !index {
+			continue
+		}
+		records[file.Language] = append(records[file.Language], b.searchRecord(file, fields))
+	}
+
+	for _, lang := range languageCodes() {
+		// Leave the HTML characters unescaped to keep the index small
+		var index bytes.Buffer
+		encoder := json.NewEncoder(&index)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(records[lang]); err != nil {
+			return fmt.Errorf("error writing search index: %w", err)
+		}
+		indexPath := filepath.Join(b.outputDir, languageDir(lang), searchIndexFile)
+		if err := filesystem.Create(indexPath, index.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Build the search record for a page with the given fields
+func (b *Builder) searchRecord(file FileInfo, fields []string) map[string]interface{} {
+	text := plainText(string(file.Content))
+	record := make(map[string]interface{}, len(fields))
+
+	for _, field := range fields {
+		switch field {
+		case "title":
+			title := metaString(file.MetaData, "title")
+			if title == "" {
+				title = file.Name
+			}
+			record["title"] = title
+		case "url":
+			record["url"] = file.OutputPath
+		case "summary":
+			record["summary"] = pageSummary(file, config.Search.SummaryLength)
+		case "tags":
+			record["tags"] = append([]string{}, metaStrings(file.MetaData, "tags")...)
+		case "section":
+			record["section"] = file.ContentType
+		case "content":
+			if config.Search.Tokenize {
+				record["terms"] = strings.Join(searchTerms(text), " ")
+			} else {
+				record["content"] = text
+			}
+		default:
+			if value, exists := file.MetaData[field]; exists {
+				// Nested front matter has to have string keys to be written as JSON
+				record[field] = stringKeys(value)
+			}
+		}
+	}
+
+	return record
+}
+
+// Returns every file in the directory map, sorted by permalink
+func sortedFiles(dirsMap map[string]DirectoryInfo) []FileInfo {
+	var files []FileInfo
+	for _, dirInfo := range dirsMap {
+		files = append(files, dirInfo.Files...)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].OutputPath < files[j].OutputPath
+	})
+	return files
+}
+
+// Returns the summary of a page from its description, or the start of its text
+func pageSummary(file FileInfo, length int) string {
+	if description := metaString(file.MetaData, "description"); description != "" {
+		return description
+	}
+	return truncateText(plainText(string(file.Content)), length)
+}
+
+// Strip the markup from rendered HTML, leaving the text with single spaces
+func plainText(content string) string {
+	content = headingAnchorPattern.ReplaceAllString(content, "")
+	content = htmlTagPattern.ReplaceAllString(content, " ")
+	return strings.Join(strings.Fields(html.UnescapeString(content)), " ")
+}
+
+// Shorten text to at most the given number of characters, breaking between
+// words. Text without a space, like CJK text or a long URL, is cut between
+// characters so the result stays valid UTF-8.
+func truncateText(text string, length int) string {
+	runes := []rune(text)
+	if length <= 0 || len(runes) <= length {
+		return text
+	}
+	prefix := string(runes[:length])
+	cut := strings.LastIndex(prefix, " ")
+	if cut <= 0 {
+		cut = len(prefix)
+	}
+	return strings.TrimRight(prefix[:cut], ".,;: ") + "…"
+}
+
+// Split text into its unique, stemmed words, leaving out common words
+func searchTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(char rune) bool {
+		return !unicode.IsLetter(char) && !unicode.IsNumber(char)
+	})
+
+	seen := make(map[string]bool)
+	var terms []string
+	for _, word := range words {
+		if len(word) < 2 || searchStopWords[word] {
+			continue
+		}
+		term := stem(word)
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// A light English stemmer that removes common suffixes, so "searching",
+// "searched" and "searches" all become "search". Words with other letters
+// are left as they are
+func stem(word string) string {
+	if len(word) <= 3 || !asciiWordPattern.MatchString(word) {
+		return word
+	}
+
+	for _, rule := range stemSuffixes {
+		if !strings.HasSuffix(word, rule.suffix) {
+			continue
+		}
+		stemmed := strings.TrimSuffix(word, rule.suffix) + rule.replace
+		if len(stemmed) < 3 {
+			break
+		}
+		word = stemmed
+
+		// Running becomes run, not runn
+		if rule.replace == "" && rule.suffix != "s" && len(word) > 3 {
+			last := word[len(word)-1]
+			if last == word[len(word)-2] && !strings.ContainsRune("aeiouls", rune(last)) {
+				word = word[:len(word)-1]
+			}
+		}
+		break
+	}
+
+	// Make and making both become mak
+	if len(word) > 3 && strings.HasSuffix(word, "e") {
+		word = strings.TrimSuffix(word, "e")
+	}
+	return word
+}
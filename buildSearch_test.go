@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestBuilder_BuildSearchIndex(t *testing.T) {
+	restoreConfig(t)
+	outputDir := t.TempDir()
+	config.Search = SearchConfig{Enabled: true, Fields: defaultSearchFields, SummaryLength: 20}
+
+	dirsMap := map[string]DirectoryInfo{
+		"content": {Files: []FileInfo{
+			{
+				Name:        "searching",
+				OutputPath:  "/searching/",
+				ContentType: "post",
+				MetaData:    map[string]interface{}{"title": "Searching", "tags": []interface{}{"go"}},
+				Content:     `<h2 id="intro">Intro <a class="anchor" href="#intro">#</a></h2><p>The pages are searched &amp; found offline</p>`,
+			},
+			{
+				Name:       "search",
+				OutputPath: "/search/",
+				MetaData:   map[string]interface{}{"title": "Search", "index": false},
+			},
+		}},
+	}
+
+	b := Builder{outputDir: outputDir}
+	if err := b.buildSearchIndex(dirsMap); err != nil {
+		t.Fatalf("buildSearchIndex returned an error: %v", err)
+	}
+
+	var records []map[string]interface{}
+	content, _ := os.ReadFile(filepath.Join(outputDir, searchIndexFile))
+	if err := json.Unmarshal(content, &records); err != nil {
+		t.Fatalf("Invalid search index: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Pages with index: false should be left out. Got: %v", records)
+	}
+
+	record := records[0]
+	if record["url"] != "/searching/" || record["section"] != "post" || record["title"] != "Searching" {
+		t.Errorf("Record mismatch. Got: %v", record)
+	}
+	if want := "Intro The pages are searched & found offline"; record["content"] != want {
+		t.Errorf("Content mismatch. Got: %q, Want: %q", record["content"], want)
+	}
+	if want := "Intro The pages are…"; record["summary"] != want {
+		t.Errorf("Summary mismatch. Got: %q, Want: %q", record["summary"], want)
+	}
+
+	// Tokenized records hold the stemmed terms instead of the content
+	config.Search.Tokenize = true
+	config.Search.Fields = []string{"url", "content"}
+	record = b.searchRecord(dirsMap["content"].Files[0], config.Search.Fields)
+	if want := "intro pag search found offlin"; record["terms"] != want || len(record) != 2 {
+		t.Errorf("Terms mismatch. Got: %v, Want: %q", record, want)
+	}
+}
+
+func TestStem(t *testing.T) {
+	words := map[string]string{
+		"searching": "search",
+		"searches":  "search",
+		"running":   "run",
+		"making":    "mak",
+		"make":      "mak",
+		"studies":   "study",
+		"classes":   "class",
+		"status":    "status",
+		"cat":       "cat",
+		"café":      "café",
+	}
+	for word, want := range words {
+		if got := stem(word); got != want {
+			t.Errorf("stem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		text   string
+		length int
+		want   string
+	}{
+		{"Short text", 20, "Short text"},
+		{"Break between the words", 12, "Break…"},
+		{"Trim the end. Of a sentence", 15, "Trim the end…"},
+		// Text without spaces is cut between characters, not bytes
+		{"日本語のテキストです", 4, "日本語の…"},
+		{"https://example.com/é/é/é", 22, "https://example.com/é/…"},
+	}
+	for _, tt := range tests {
+		got := truncateText(tt.text, tt.length)
+		if got != tt.want || !utf8.ValidString(got) {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", tt.text, tt.length, got, tt.want)
+		}
+	}
+}
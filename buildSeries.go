@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SeriesConfig controls the index pages of the series
+type SeriesConfig struct {
+	// Path is the permalink the index page of each series is written under,
+	// e.g. /series/go-tutorial/ - defaults to /series/
+	// Leave it empty for no index pages
+	Path string `yaml:"path"`
+}
+
+// Series is the series a page is part of, used in templates as .Series
+// Pages join a series with `series:` and are ordered by `series_order:`
+type Series struct {
+	Name     string    // The name of the series
+	URL      string    // The permalink of the index page of the series
+	Position int       // The position of the page in the series, starting at 1
+	Total    int       // The number of pages in the series
+	Pages    Pages     // Every page in the series, in order
+	Prev     *FileInfo // The page before this one, nil for the first page
+	Next     *FileInfo // The page after this one, nil for the last page
+}
+
+// The template used for the index page of each series, list.tmpl is used when a site doesn't have one
+const seriesTemplate = "series.tmpl"
+
+// **********  Private Series Methods  **********
+
+// Link the pages of each series, in their own language. Pages are ordered by
+// series_order, then by date and permalink; pages without an order go last.
+func (b *Builder) buildSeries(dirsMap map[string]DirectoryInfo) error {
+	var keys []string
+	series := make(map[string]Pages)
+	orders := make(map[string]int)
+	for _, file := range sortedFiles(dirsMap) {
+		name := strings.TrimSpace(metaString(file.MetaData, "series"))
+		if name == "" {
+			continue
+		}
+		order, err := seriesOrder(file.MetaData)
+		if err != nil {
+			if err := b.addError(file.Path, "", err); err != nil {
+				return err
+			}
+		}
+
+		key := file.Language + ":" + name
+		if _, exists := series[key]; !exists {
+			keys = append(keys, key)
+		}
+		series[key] = append(series[key], file)
+		orders[file.Path] = order
+	}
+
+	linked := make(map[string]*Series)
+	for _, key := range keys {
+		pages := series[key]
+		sort.SliceStable(pages, func(i, j int) bool {
+			orderI, orderJ := orders[pages[i].Path], orders[pages[j].Path]
+			if orderI != orderJ {
+				return orderJ == 0 || (orderI != 0 && orderI < orderJ)
+			}
+			if !pages[i].Date.Equal(pages[j].Date) {
+				return pages[i].Date.Before(pages[j].Date)
+			}
+			return pages[i].OutputPath < pages[j].OutputPath
+		})
+
+		name := strings.SplitN(key, ":", 2)[1]
+		url := ""
+		if config.Series.Path != "" {
+			url = listPermalink(seriesDir(pages[0].Language, name))
+		}
+		for i, page := range pages {
+			entry := &Series{Name: name, URL: url, Position: i + 1, Total: len(pages), Pages: pages}
+			if i > 0 {
+				entry.Prev = &pages[i-1]
+			}
+			if i < len(pages)-1 {
+				entry.Next = &pages[i+1]
+			}
+			linked[page.Path] = entry
+		}
+	}
+
+	for dirKey, dirInfo := range dirsMap {
+		for i, file := range dirInfo.Files {
+			dirInfo.Files[i].Series = linked[file.Path]
+		}
+		dirsMap[dirKey] = dirInfo
+	}
+	return nil
+}
+
+// Write the index page of each series, listing its pages in order
+func (b *Builder) buildSeriesPages(dirsMap map[string]DirectoryInfo) error {
+	if config.Series.Path == "" {
+		return nil
+	}
+
+	written := make(map[string]bool)
+	for _, file := range sortedFiles(dirsMap) {
+		if file.Series == nil || written[file.Series.URL] {
+			continue
+		}
+		written[file.Series.URL] = true
+
+		dir := seriesDir(file.Language, file.Series.Name)
+		if err := b.writeListPage(dir, seriesTemplate, file.Language, file.Series.Name, file.Series.Pages); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the directory of the index page of a series, e.g. series/go-tutorial
+func seriesDir(lang string, name string) string {
+	return path.Join(languageDir(lang), config.Series.Path, slugify(name))
+}
+
+// Returns the position of a page in its series from `series_order:`, or 0 when it isn't set
+func seriesOrder(metaData map[string]interface{}) (int, error) {
+	switch value := metaData["series_order"].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return value, nil
+	case string:
+		if order, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			return order, nil
+		}
+	}
+	return 0, fmt.Errorf("series_order should be a number, got %v", metaData["series_order"])
+}
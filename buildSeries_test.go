@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuilder_BuildSeries(t *testing.T) {
+	restoreConfig(t)
+	root := t.TempDir()
+	templateDir := filepath.Join(root, "template")
+	os.MkdirAll(templateDir, 0755)
+	os.WriteFile(filepath.Join(templateDir, "fullpage.tmpl"), []byte(`{{ .Content }}`), 0644)
+	os.WriteFile(filepath.Join(templateDir, "list.tmpl"), []byte(`{{ .Title }}:{{ range .Files }} {{ .Name }}{{ end }}`), 0644)
+
+	config.UglyURLs = false
+	config.Series = SeriesConfig{Path: "/series/"}
+
+	part := func(name string, order interface{}) FileInfo {
+		metaData := map[string]interface{}{"series": "Go Tutorial"}
+		if order != nil {
+			metaData["series_order"] = order
+		}
+		return FileInfo{Name: name, Path: "post/" + name + ".md", OutputPath: "/post/" + name + "/", MetaData: metaData}
+	}
+	dirsMap := map[string]DirectoryInfo{
+		"content/post": {Files: []FileInfo{
+			part("tutorial-3", 3),
+			part("tutorial-notes", nil),
+			part("tutorial-1", "1"),
+			part("tutorial-2", 2),
+			{Name: "other", Path: "post/other.md", OutputPath: "/post/other/"},
+		}},
+	}
+
+	b := Builder{rootPath: root, templateDir: templateDir, outputDir: filepath.Join(root, "web")}
+	if err := b.buildSeries(dirsMap); err != nil {
+		t.Fatalf("buildSeries returned an error: %v", err)
+	}
+
+	series := make(map[string]*Series)
+	for _, file := range dirsMap["content/post"].Files {
+		series[file.Name] = file.Series
+	}
+	if series["other"] != nil {
+		t.Errorf("Expected no series for a page without one. Got: %v", series["other"])
+	}
+
+	second := series["tutorial-2"]
+	if second == nil || second.Position != 2 || second.Total != 4 || second.URL != "/series/go-tutorial/" {
+		t.Fatalf("Expected part 2 of 4 in /series/go-tutorial/. Got: %+v", second)
+	}
+	if second.Prev == nil || second.Prev.Name != "tutorial-1" || second.Next == nil || second.Next.Name != "tutorial-3" {
+		t.Errorf("Expected tutorial-1 before and tutorial-3 after. Got: %+v", second)
+	}
+	// Pages without an order go last
+	if last := series["tutorial-notes"]; last == nil || last.Position != 4 || last.Next != nil {
+		t.Errorf("Expected the page without an order to be last. Got: %+v", last)
+	}
+	if first := series["tutorial-1"]; first == nil || first.Prev != nil {
+		t.Errorf("Expected no page before the first. Got: %+v", first)
+	}
+
+	if err := b.initTemplates(); err != nil {
+		t.Fatalf("initTemplates returned an error: %v", err)
+	}
+	if err := b.buildSeriesPages(dirsMap); err != nil {
+		t.Fatalf("buildSeriesPages returned an error: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(root, "web", "series", "go-tutorial", "index.html"))
+	if err != nil {
+		t.Fatalf("Series page not written: %v", err)
+	}
+	want := "Go Tutorial: tutorial-1 tutorial-2 tutorial-3 tutorial-notes"
+	if got := strings.TrimSpace(string(content)); got != want {
+		t.Errorf("Series page mismatch. Got: %q, Want: %q", got, want)
+	}
+}
+
+func TestSeriesOrder(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		want    int
+		wantErr bool
+	}{
+		{nil, 0, false},
+		{3, 3, false},
+		{" 4 ", 4, false},
+		{"first", 0, true},
+		{1.5, 0, true},
+	}
+	for _, test := range tests {
+		metaData := map[string]interface{}{}
+		if test.value != nil {
+			metaData["series_order"] = test.value
+		}
+		got, err := seriesOrder(metaData)
+		if got != test.want || (err != nil) != test.wantErr {
+			t.Errorf("seriesOrder(%v) = %d, %v. Want: %d, error %v", test.value, got, err, test.want, test.wantErr)
+		}
+	}
+}
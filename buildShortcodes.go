@@ -0,0 +1,436 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Shortcode holds the data passed into a shortcode template
+type Shortcode struct {
+	Name   string            // The name of the shortcode (e.g. "figure")
+	Args   []string          // Positional parameters
+	Params map[string]string // Named parameters
+	Inner  template.HTML     // The rendered content between the opening and closing tags
+	Page   FileInfo          // The file the shortcode is used in
+	Site   Site              // The data shared by every page, e.g. .Site.Data
+}
+
+// Holds a shortcode found in markdown content before it is rendered
+type shortcodeCall struct {
+	Name     string            // The name of the shortcode
+	Args     []string          // Positional parameters
+	Params   map[string]string // Named parameters
+	Inner    string            // The raw markdown between the tags (may contain placeholders)
+	HasInner bool              // Whether the shortcode has a closing tag
+}
+
+// The directory (relative to the template directory) that holds shortcodes
+const shortcodesDir = "shortcodes"
+
+// Delimiters for shortcodes in markdown content
+const (
+	shortcodeOpen        = "{{<"
+	shortcodeClose       = ">}}"
+	shortcodeEscapeOpen  = "{{</*"
+	shortcodeEscapeClose = "*/>}}"
+)
+
+// **********  Public Shortcode Methods  **********
+
+// Get returns a parameter by position (int) or by name (string).
+// It returns an empty string when the parameter is not set.
+func (s Shortcode) Get(key interface{}) string {
+	switch k := key.(type) {
+	case int:
+		if k >= 0 && k < len(s.Args) {
+			return s.Args[k]
+		}
+	case string:
+		if value, exists := s.Params[k]; exists {
+			return value
+		}
+		if index, err := strconv.Atoi(k); err == nil {
+			return s.Get(index)
+		}
+	}
+	return ""
+}
+
+// **********  Private Shortcode Methods  **********
+
+// Replace the shortcodes in the content with placeholders.
+// Returns the content with placeholders and the shortcodes in render order:
+// nested shortcodes always come before the shortcode that contains them.
+func (b *Builder) extractShortcodes(content string) (string, []shortcodeCall, error) {
+	var calls []shortcodeCall
+	output, err := b.parseShortcodes(content, &calls)
+	return output, calls, err
+}
+
+// Parse shortcodes out of the content, appending each one to calls.
+// Shortcodes in fenced code blocks and inline code are left as they are, so
+// code can show them, but escaped shortcodes are unescaped there too.
+func (b *Builder) parseShortcodes(content string, calls *[]shortcodeCall) (string, error) {
+	var output strings.Builder
+	code := codeRanges(content)
+
+	pos := 0
+	for {
+		start := strings.Index(content[pos:], shortcodeOpen)
+		if start == -1 {
+			output.WriteString(content[pos:])
+			return output.String(), nil
+		}
+		start += pos
+		output.WriteString(content[pos:start])
+
+		// Escaped shortcodes are written out literally without the comment markers
+		if strings.HasPrefix(content[start:], shortcodeEscapeOpen) {
+			end := strings.Index(content[start:], shortcodeEscapeClose)
+			if end == -1 {
+				return "", fmt.Errorf("unclosed escaped shortcode: %s", firstLine(content[start:]))
+			}
+			output.WriteString(shortcodeOpen + content[start+len(shortcodeEscapeOpen):start+end] + shortcodeClose)
+			pos = start + end + len(shortcodeEscapeClose)
+			continue
+		}
+		if inRanges(code, start) {
+			output.WriteString(shortcodeOpen)
+			pos = start + len(shortcodeOpen)
+			continue
+		}
+
+		end := strings.Index(content[start:], shortcodeClose)
+		if end == -1 {
+			return "", fmt.Errorf("unclosed shortcode: %s", firstLine(content[start:]))
+		}
+		tag := strings.TrimSpace(content[start+len(shortcodeOpen) : start+end])
+		pos = start + end + len(shortcodeClose)
+
+		if strings.HasPrefix(tag, "/") {
+			return "", fmt.Errorf("closing shortcode without an opening tag: %s", tag)
+		}
+
+		call, err := b.parseShortcodeTag(tag)
+		if err != nil {
+			return "", err
+		}
+
+		// Look for a matching closing tag to capture the inner content
+		closePos, closeLen := findShortcodeClose(content, pos, call.Name, code)
+		if closePos != -1 {
+			inner, err := b.parseShortcodes(content[pos:closePos], calls)
+			if err != nil {
+				return "", err
+			}
+			call.Inner = inner
+			call.HasInner = true
+			pos = closePos + closeLen
+		}
+
+		*calls = append(*calls, call)
+		output.WriteString(shortcodePlaceholder(len(*calls) - 1))
+	}
+}
+
+// Parse the name and parameters of a shortcode tag.
+// Parameters can be positional or named (key="value"), quoted or bare.
+func (b *Builder) parseShortcodeTag(tag string) (shortcodeCall, error) {
+	tokens, err := splitShortcodeArgs(tag)
+	if err != nil {
+		return shortcodeCall{}, fmt.Errorf("invalid shortcode %q: %w", tag, err)
+	}
+	if len(tokens) == 0 {
+		return shortcodeCall{}, fmt.Errorf("shortcode is missing a name")
+	}
+
+	call := shortcodeCall{
+		Name:   tokens[0],
+		Params: make(map[string]string),
+	}
+	for _, token := range tokens[1:] {
+		if key, value, isNamed := strings.Cut(token, "="); isNamed && key != "" && !strings.ContainsAny(key, `"`) {
+			call.Params[key] = unquote(value)
+		} else {
+			call.Args = append(call.Args, unquote(token))
+		}
+	}
+
+	return call, nil
+}
+
+// Render the shortcodes and substitute the output for their placeholders
+func (b *Builder) renderShortcodes(html string, calls []shortcodeCall, page FileInfo) (string, error) {
+	rendered := make([]string, len(calls))
+
+	for i, call := range calls {
+		data := Shortcode{
+			Name:   call.Name,
+			Args:   call.Args,
+			Params: call.Params,
+			Page:   page,
+			Site:   b.sites[page.Language],
+		}
+
+		// Render the inner content as markdown, filling in nested shortcodes
+		if call.HasInner {
+			var inner bytes.Buffer
+			if err := b.markdown(page).Convert([]byte(call.Inner), &inner); err != nil {
+				return "", fmt.Errorf("error rendering shortcode %s: %w", call.Name, err)
+			}
+			data.Inner = template.HTML(substituteShortcodes(inner.String(), rendered[:i]))
+		}
+
+		output, err := b.executeShortcode(data)
+		if err != nil {
+			return "", err
+		}
+		rendered[i] = output
+	}
+
+	return substituteShortcodes(html, rendered), nil
+}
+
+// Execute the template for a shortcode
+func (b *Builder) executeShortcode(data Shortcode) (string, error) {
+	name := shortcodesDir + "/" + data.Name + ".tmpl"
+	if b.contentTemplates.Lookup(name) == nil {
+		return "", fmt.Errorf("shortcode template not found: %s", name)
+	}
+
+	var output bytes.Buffer
+	if err := b.contentTemplates.ExecuteTemplate(&output, name, data); err != nil {
+		return "", fmt.Errorf("error rendering shortcode %s: %w", data.Name, err)
+	}
+
+	return output.String(), nil
+}
+
+// Add the built in shortcodes that the site doesn't override
+func (b *Builder) addDefaultShortcodes() error {
+	for name, source := range DefaultShortcodes {
+		templateName := shortcodesDir + "/" + name + ".tmpl"
+		if b.templates.Lookup(templateName) != nil {
+			continue
+		}
+		if _, err := b.templates.New(templateName).Parse(source); err != nil {
+			return fmt.Errorf("failed to parse default shortcode %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Replace placeholders with their rendered shortcodes.
+// Placeholders on their own line are wrapped in a paragraph by the markdown
+// renderer, so we remove that wrapper to keep block level output valid.
+func substituteShortcodes(html string, rendered []string) string {
+	for i, output := range rendered {
+		placeholder := shortcodePlaceholder(i)
+		html = strings.ReplaceAll(html, "<p>"+placeholder+"</p>", output)
+		html = strings.ReplaceAll(html, placeholder, output)
+	}
+	return html
+}
+
+// Returns the placeholder for the shortcode at the given index
+func shortcodePlaceholder(index int) string {
+	return fmt.Sprintf("RPSC-%d-RPSC", index)
+}
+
+// Find the closing tag for a shortcode in the content after the given position,
+// returning its position and length. Shortcodes of the same name nested inside
+// it are matched with their own closing tags. Escaped shortcodes and shortcodes
+// in code are skipped.
+func findShortcodeClose(content string, from int, name string, code [][2]int) (int, int) {
+	depth := 0
+	offset := from
+	for {
+		start := strings.Index(content[offset:], shortcodeOpen)
+		if start == -1 {
+			return -1, 0
+		}
+		start += offset
+		end := strings.Index(content[start:], shortcodeClose)
+		if end == -1 {
+			return -1, 0
+		}
+		offset = start + end + len(shortcodeClose)
+		if strings.HasPrefix(content[start:], shortcodeEscapeOpen) || inRanges(code, start) {
+			continue
+		}
+
+		tag := strings.TrimSpace(content[start+len(shortcodeOpen) : start+end])
+		if strings.HasPrefix(tag, "/") {
+			if strings.TrimSpace(tag[1:]) != name {
+				continue
+			}
+			if depth == 0 {
+				return start, end + len(shortcodeClose)
+			}
+			depth--
+		} else if fields := strings.Fields(tag); len(fields) > 0 && fields[0] == name {
+			depth++
+		}
+	}
+}
+
+// Returns the byte ranges of the fenced code blocks and inline code spans in
+// markdown content
+func codeRanges(content string) [][2]int {
+	var ranges [][2]int
+	atLineStart := true
+	for pos := 0; pos < len(content); {
+		if atLineStart {
+			if fence := codeFence(content[pos:]); fence != "" {
+				end := closingFence(content, pos, fence)
+				ranges = append(ranges, [2]int{pos, end})
+				pos = end
+				continue
+			}
+		}
+		if content[pos] == '`' {
+			run := backtickRun(content[pos:])
+			if end := closingBackticks(content, pos+run, run); end != -1 {
+				ranges = append(ranges, [2]int{pos, end})
+				pos = end
+			} else {
+				pos += run
+			}
+			atLineStart = false
+			continue
+		}
+		atLineStart = content[pos] == '\n'
+		pos++
+	}
+	return ranges
+}
+
+// Returns the fence that opens a code block at the start of the text, e.g. ```
+// or ~~~, or an empty string when the text doesn't start with one
+func codeFence(text string) string {
+	indent := len(text) - len(strings.TrimLeft(text, " "))
+	if indent > 3 {
+		return ""
+	}
+	text = text[indent:]
+	if text == "" || (text[0] != '`' && text[0] != '~') {
+		return ""
+	}
+	run := len(text) - len(strings.TrimLeft(text, text[:1]))
+	if run < 3 {
+		return ""
+	}
+	return text[:run]
+}
+
+// Returns the end of the line that closes a fenced code block opened at pos,
+// or the end of the content when the block isn't closed
+func closingFence(content string, pos int, fence string) int {
+	lineStart := strings.IndexByte(content[pos:], '\n')
+	if lineStart == -1 {
+		return len(content)
+	}
+	lineStart += pos + 1
+	for lineStart < len(content) {
+		lineEnd := len(content)
+		if newline := strings.IndexByte(content[lineStart:], '\n'); newline != -1 {
+			lineEnd = lineStart + newline + 1
+		}
+		line := content[lineStart:lineEnd]
+		if closing := codeFence(line); strings.HasPrefix(closing, fence) {
+			if strings.TrimSpace(strings.TrimLeft(line, " ")[len(closing):]) == "" {
+				return lineEnd
+			}
+		}
+		lineStart = lineEnd
+	}
+	return len(content)
+}
+
+// Returns the end of the run of backticks that closes an inline code span
+// opened with a run of the given length, or -1 when it isn't closed
+func closingBackticks(content string, from int, length int) int {
+	for pos := from; pos < len(content); {
+		next := strings.IndexByte(content[pos:], '`')
+		if next == -1 {
+			return -1
+		}
+		pos += next
+		run := backtickRun(content[pos:])
+		if run == length {
+			return pos + run
+		}
+		pos += run
+	}
+	return -1
+}
+
+// Returns the number of backticks at the start of the text
+func backtickRun(text string) int {
+	return len(text) - len(strings.TrimLeft(text, "`"))
+}
+
+// Check if a position is in one of the ranges
+func inRanges(ranges [][2]int, pos int) bool {
+	for _, r := range ranges {
+		if pos >= r[0] && pos < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// Split shortcode arguments on whitespace, keeping quoted values together.
+// A backslash escapes the next character inside a quoted value.
+func splitShortcodeArgs(tag string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+	escaped := false
+
+	for _, r := range tag {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && inQuotes:
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuotes:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens, nil
+}
+
+// Remove surrounding double quotes from a value
+func unquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// Returns the first line of the content for error messages
+func firstLine(content string) string {
+	line, _, _ := strings.Cut(content, "\n")
+	return line
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestBuilder_ExtractShortcodes(t *testing.T) {
+	var b Builder
+	content := `Intro {{< figure src="/img/a.jpg" caption="A \"quoted\" caption" >}}
+{{< callout warning >}}Inner {{< youtube abc123 >}}{{< /callout >}}
+Escaped {{</* figure src="x" */>}}`
+
+	output, calls, err := b.extractShortcodes(content)
+	if err != nil {
+		t.Fatalf("Failed to extract shortcodes: %s", err)
+	}
+
+	// Nested shortcodes are listed before the shortcode that contains them
+	if len(calls) != 3 {
+		t.Fatalf("Shortcode count mismatch. Got: %d, Want: 3", len(calls))
+	}
+	if calls[0].Name != "figure" || calls[1].Name != "youtube" || calls[2].Name != "callout" {
+		t.Errorf("Shortcode order mismatch. Got: %s, %s, %s", calls[0].Name, calls[1].Name, calls[2].Name)
+	}
+
+	want := "Intro RPSC-0-RPSC\nRPSC-2-RPSC\nEscaped {{< figure src=\"x\" >}}"
+	if output != want {
+		t.Errorf("Output mismatch. Got: %q, Want: %q", output, want)
+	}
+
+	if calls[2].Inner != "Inner RPSC-1-RPSC" || !calls[2].HasInner {
+		t.Errorf("Inner content mismatch. Got: %q", calls[2].Inner)
+	}
+}
+
+func TestBuilder_ExtractShortcodesNested(t *testing.T) {
+	var b Builder
+	content := "{{< details Outer >}}A {{< details Inner >}}B{{< /details >}} C{{< /details >}}"
+
+	output, calls, err := b.extractShortcodes(content)
+	if err != nil {
+		t.Fatalf("Failed to extract shortcodes: %s", err)
+	}
+
+	// A shortcode of the same name nested inside is closed by its own tag
+	if output != "RPSC-1-RPSC" || len(calls) != 2 {
+		t.Fatalf("Output mismatch. Got: %q with %d shortcodes", output, len(calls))
+	}
+	if calls[0].Inner != "B" || calls[1].Inner != "A RPSC-0-RPSC C" {
+		t.Errorf("Inner content mismatch. Got: %q, %q", calls[0].Inner, calls[1].Inner)
+	}
+}
+
+func TestBuilder_ExtractShortcodesInCode(t *testing.T) {
+	var b Builder
+	content := "Use `{{< youtube id >}}` here.\n\n```md\n{{< callout >}}Hi{{< /callout >}}\n{{</* figure */>}}\n```\n\n" +
+		"~~~\n{{< youtube a >}}\n~~~\n{{< youtube b >}}"
+
+	output, calls, err := b.extractShortcodes(content)
+	if err != nil {
+		t.Fatalf("Failed to extract shortcodes: %s", err)
+	}
+
+	// Shortcodes in code are shown as they are, escaped ones are still unescaped
+	want := "Use `{{< youtube id >}}` here.\n\n```md\n{{< callout >}}Hi{{< /callout >}}\n{{< figure >}}\n```\n\n" +
+		"~~~\n{{< youtube a >}}\n~~~\nRPSC-0-RPSC"
+	if output != want {
+		t.Errorf("Output mismatch.\nGot:  %q\nWant: %q", output, want)
+	}
+	if len(calls) != 1 || calls[0].Args[0] != "b" {
+		t.Errorf("Expected only the shortcode outside code. Got: %+v", calls)
+	}
+}
+
+func TestShortcode_Get(t *testing.T) {
+	var b Builder
+	call, err := b.parseShortcodeTag(`figure /img/a.jpg caption="A \"quoted\" caption" wide`)
+	if err != nil {
+		t.Fatalf("Failed to parse shortcode: %s", err)
+	}
+	shortcode := Shortcode{Name: call.Name, Args: call.Args, Params: call.Params}
+
+	tests := []struct {
+		key  interface{}
+		want string
+	}{
+		{0, "/img/a.jpg"},
+		{1, "wide"},
+		{"1", "wide"},
+		{2, ""},
+		{"caption", `A "quoted" caption`},
+		{"missing", ""},
+	}
+	for _, tt := range tests {
+		if got := shortcode.Get(tt.key); got != tt.want {
+			t.Errorf("Get(%v) mismatch. Got: %q, Want: %q", tt.key, got, tt.want)
+		}
+	}
+}
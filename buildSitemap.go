@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// The file the sitemap is written to, in the output directory
+const sitemapFile = "sitemap.xml"
+
+// The elements of a sitemap, with links to the translations of each page
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	XHTML   string       `xml:"xmlns:xhtml,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string        `xml:"loc"`
+	LastMod    string        `xml:"lastmod,omitempty"`
+	Alternates []sitemapLink `xml:"xhtml:link"`
+}
+
+type sitemapLink struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+// **********  Private Sitemap Methods  **********
+
+// Write the sitemap with every page and list page of the site.
+// Pages with `index: false` are left out, and translated pages link to
+// each of their translations.
+func (b *Builder) buildSitemap(dirsMap map[string]DirectoryInfo) error {
+	logger.Info("Building sitemap")
+
+	urlSet := sitemapURLSet{
+		Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		XHTML: "http://www.w3.org/1999/xhtml",
+	}
+	for _, file := range sortedFiles(dirsMap) {
+		if index, exists := file.MetaData["index"].(bool); exists && !index {
+			continue
+		}
+		url := sitemapURL{Loc: absoluteURL(file.OutputPath), LastMod: file.Date.Format(defaultDateFormat)}
+		if len(file.Translations) > 0 {
+			for _, page := range append([]FileInfo{file}, file.Translations...) {
+				url.Alternates = append(url.Alternates, sitemapLink{
+					Rel:      "alternate",
+					Hreflang: page.Language,
+					Href:     absoluteURL(page.OutputPath),
+				})
+			}
+		}
+		urlSet.URLs = append(urlSet.URLs, url)
+	}
+
+	// The list pages written for directories without an index page
+	var lists []sitemapURL
+	for _, dirInfo := range dirsMap {
+		if !dirInfo.HasIndex && dirInfo.NumFiles > 0 {
+			lists = append(lists, sitemapURL{Loc: absoluteURL(listPermalink(dirInfo.Path))})
+		}
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].Loc < lists[j].Loc })
+	urlSet.URLs = append(urlSet.URLs, lists...)
+
+	output, err := xml.MarshalIndent(urlSet, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error writing sitemap: %w", err)
+	}
+	return filesystem.Create(filepath.Join(b.outputDir, sitemapFile), xml.Header+string(output))
+}
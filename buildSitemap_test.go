@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuilder_BuildSitemap(t *testing.T) {
+	restoreConfig(t)
+	outputDir := t.TempDir()
+	config.UglyURLs = false
+	config.URL = "https://example.com/"
+
+	spanish := FileInfo{Name: "about", OutputPath: "/es/about/", Language: "es"}
+	dirsMap := map[string]DirectoryInfo{
+		"content": {Path: ".", HasIndex: true, NumFiles: 2, Files: []FileInfo{
+			{Name: "about", OutputPath: "/about/", Language: "en", Translations: []FileInfo{spanish}},
+			{Name: "search", OutputPath: "/search/", MetaData: map[string]interface{}{"index": false}},
+		}},
+		"content/post": {Path: "post", NumFiles: 1, Files: []FileInfo{
+			{Name: "hello", OutputPath: "/post/hello/"},
+		}},
+	}
+
+	b := Builder{outputDir: outputDir}
+	if err := b.buildSitemap(dirsMap); err != nil {
+		t.Fatalf("buildSitemap returned an error: %v", err)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(outputDir, sitemapFile))
+	sitemap := string(content)
+	for _, want := range []string{
+		"<loc>https://example.com/about/</loc>",
+		`<xhtml:link rel="alternate" hreflang="es" href="https://example.com/es/about/">`,
+		"<loc>https://example.com/post/hello/</loc>",
+		"<loc>https://example.com/post/</loc>",
+	} {
+		if !strings.Contains(sitemap, want) {
+			t.Errorf("Sitemap is missing %s. Got:\n%s", want, sitemap)
+		}
+	}
+	if strings.Contains(sitemap, "/search/") {
+		t.Error("Pages with index: false should be left out of the sitemap")
+	}
+}
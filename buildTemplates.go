@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Layout holds a content template that extends a base layout
+// Keyed by the template name
+type Layout struct {
+	Base   string // The name of the base layout template (e.g. "fullpage.tmpl")
+	Source string // The raw template source, parsed into a clone for each page
+}
+
+// The default base layout used when a template does not extend another one
+const defaultBaseLayout = "fullpage.tmpl"
+
+// The directory (relative to the template directory) that holds partials
+const partialsDir = "partials"
+
+// Matches the extends declaration at the top of a content template, e.g.
+// {{/* extends "landing.tmpl" */}}
+var extendsPattern = regexp.MustCompile(`^\s*\{\{-?\s*/\*\s*extends\s+"([^"]+)"\s*\*/\s*-?\}\}`)
+
+// **********  Private Template Methods  **********
+
+// Parse the templates and store them on the builder
+// Templates are loaded recursively and named by their path relative to the
+// template directory (e.g. "partials/header.tmpl", "post/single.tmpl").
+// Templates that extend a base layout are kept aside as layouts so they can
+// be parsed per page, while everything else is parsed into the shared set.
+func (b *Builder) initTemplates() error {
+	b.templates = template.New("").Funcs(b.templateFuncs(nil, config.DefaultLanguage))
+	b.layouts = make(map[string]Layout)
+	b.partials = make(map[string]template.HTML)
+
+	err := filepath.Walk(b.templateDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".tmpl" {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(b.templateDir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(relPath)
+
+		source, err := filesystem.Read(path)
+		if err != nil {
+			return err
+		}
+
+		// Keep templates that extend a base layout out of the shared set
+		if match := extendsPattern.FindStringSubmatch(source); match != nil {
+			b.layouts[name] = Layout{Base: match[1], Source: source}
+			return nil
+		}
+
+		_, err = b.templates.New(name).Parse(source)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load templates: %w", err)
+	}
+	if len(b.templates.Templates()) == 0 && len(b.layouts) == 0 {
+		return fmt.Errorf("failed to load templates: no templates found in %s", b.templateDir)
+	}
+
+	// Make sure every layout extends a template that exists
+	for name, layout := range b.layouts {
+		if b.templates.Lookup(layout.Base) == nil {
+			return fmt.Errorf("template %s extends unknown base layout %s", name, layout.Base)
+		}
+	}
+
+	// Add the built in shortcodes and partials and keep a clone to render shortcodes and
+	// render hooks with, so executing them doesn't stop the base set from
+	// being cloned for each page
+	if err := b.addDefaultShortcodes(); err != nil {
+		return err
+	}
+	if err := b.addDefaultPartials(); err != nil {
+		return err
+	}
+	contentTemplates, err := b.templates.Clone()
+	if err != nil {
+		return fmt.Errorf("failed to load templates: %w", err)
+	}
+	b.contentTemplates = contentTemplates.Funcs(b.templateFuncs(contentTemplates, config.DefaultLanguage))
+
+	return nil
+}
+
+// Render a full page from a content template.
+// Templates that extend a base layout are parsed into a clone of the template
+// set so their blocks override the base layout for this page only. Other
+// templates are rendered to a string first and passed to the default layout.
+func (b *Builder) renderPage(templateFile string, contentData interface{}, pageData PageData) (string, error) {
+	// Clone the base set so page specific blocks don't leak into other pages
+	tmpl, err := b.templates.Clone()
+	if err != nil {
+		return "", fmt.Errorf("failed to clone templates for %s: %w", templateFile, err)
+	}
+	tmpl.Funcs(b.templateFuncs(tmpl, pageData.Page.Language))
+
+	baseLayout := defaultBaseLayout
+	if layout, isChild := b.layouts[templateFile]; isChild {
+		if _, err := tmpl.New(templateFile).Parse(layout.Source); err != nil {
+			return "", fmt.Errorf("failed to parse template %s: %w", templateFile, err)
+		}
+		baseLayout = layout.Base
+	} else {
+		content, err := b.getTemplateContent(tmpl, templateFile, contentData)
+		if err != nil {
+			return "", err
+		}
+		pageData.Content = content
+	}
+
+	// Execute the base layout with the built PageData
+	var output bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&output, baseLayout, pageData); err != nil {
+		return "", err
+	}
+
+	return output.String(), nil
+}
+
+// Process the content in the pageData struct to generate templated contend
+func (b *Builder) getTemplateContent(tmpl *template.Template, templateFile string, data interface{}) (template.HTML, error) {
+	// Process the template in the metadata with the content in the metadata
+	var tmplContent bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&tmplContent, templateFile, data); err != nil {
+		return "", err
+	}
+
+	return template.HTML(tmplContent.String()), nil
+}
+
+// Returns the functions available to every template.
+// The functions are bound to the template set they execute in and the
+// language of the page, so the placeholder set used while parsing is rebound
+// on every page clone.
+func (b *Builder) templateFuncs(tmpl *template.Template, lang string) template.FuncMap {
+	return template.FuncMap{
+		// ref returns the output path of a content file, relative to the page or content directory
+		"ref": func(page FileInfo, target string) (string, error) {
+			return b.resolveRef(page, target)
+		},
+		// partial renders a template from the partials directory with the given data
+		"partial": func(name string, data ...interface{}) (template.HTML, error) {
+			return b.executePartial(tmpl, name, data)
+		},
+		// partialCached renders a partial once per language and reuses the output.
+		// Any extra arguments after the data are used as cache variants.
+		"partialCached": func(name string, data interface{}, variants ...interface{}) (template.HTML, error) {
+			key := fmt.Sprintf("%s:%s%#v", lang, name, variants)
+			if cached, exists := b.partials[key]; exists {
+				return cached, nil
+			}
+			output, err := b.executePartial(tmpl, name, []interface{}{data})
+			if err != nil {
+				return "", err
+			}
+			b.partials[key] = output
+			return output, nil
+		},
+		// absURL returns the permalink with the site URL in front of it
+		"absURL": absoluteURL,
+		// T returns a string from the i18n files in the language of the page
+		"T": func(key string, args ...interface{}) string {
+			return b.translate(lang, key, args...)
+		},
+	}
+}
+
+// Add the built in partials that the site doesn't override
+func (b *Builder) addDefaultPartials() error {
+	for name, source := range DefaultPartials {
+		templateName := partialsDir + "/" + name + ".tmpl"
+		if b.templates.Lookup(templateName) != nil {
+			continue
+		}
+		if _, err := b.templates.New(templateName).Parse(source); err != nil {
+			return fmt.Errorf("failed to parse default partial %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Execute a partial within the given template set and return the output.
+func (b *Builder) executePartial(tmpl *template.Template, name string, data []interface{}) (template.HTML, error) {
+	if tmpl == nil {
+		return "", fmt.Errorf("partial %s called outside of a page", name)
+	}
+
+	partialName := b.partialName(tmpl, name)
+	if partialName == "" {
+		return "", fmt.Errorf("partial not found: %s", name)
+	}
+
+	var context interface{}
+	if len(data) > 0 {
+		context = data[0]
+	}
+
+	var output bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&output, partialName, context); err != nil {
+		return "", err
+	}
+
+	return template.HTML(output.String()), nil
+}
+
+// Resolve a partial name to a loaded template.
+// "header", "header.tmpl" and "partials/header.tmpl" all find the same partial.
+func (b *Builder) partialName(tmpl *template.Template, name string) string {
+	fileName := name
+	if !strings.HasSuffix(fileName, ".tmpl") {
+		fileName += ".tmpl"
+	}
+	candidates := []string{name, fileName, partialsDir + "/" + fileName}
+
+	for _, candidate := range candidates {
+		if tmpl.Lookup(candidate) != nil {
+			return candidate
+		}
+	}
+
+	return ""
+}
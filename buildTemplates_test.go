@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Write the templates to a template directory and load them into a builder
+func templateBuilder(t *testing.T, templates map[string]string) Builder {
+	t.Helper()
+	templateDir := filepath.Join(t.TempDir(), "template")
+	for name, source := range templates {
+		path := filepath.Join(templateDir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(source), 0644)
+	}
+
+	b := Builder{templateDir: templateDir}
+	if err := b.initTemplates(); err != nil {
+		t.Fatalf("initTemplates returned an error: %v", err)
+	}
+	return b
+}
+
+func TestBuilder_RenderPage(t *testing.T) {
+	b := templateBuilder(t, map[string]string{
+		"fullpage.tmpl": `<head>{{ block "head" . }}base head{{ end }}</head>` +
+			`<main>{{ block "main" . }}{{ .Content }}{{ end }}</main>` +
+			`<footer>{{ block "footer" . }}base footer{{ end }}</footer>`,
+		"landing.tmpl": `{{/* extends "fullpage.tmpl" */}}` +
+			`{{ define "head" }}landing head{{ end }}{{ define "main" }}landing {{ .Title }}{{ end }}`,
+		"nested.tmpl": `{{- /* extends "fullpage.tmpl" */ -}}{{ define "main" }}nested{{ end }}`,
+		"page.tmpl":   `page {{ .Title }}`,
+	})
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		// A child overrides the blocks it defines and keeps the others from the parent
+		{"landing.tmpl", "<head>landing head</head><main>landing Hello</main><footer>base footer</footer>"},
+		{"nested.tmpl", "<head>base head</head><main>nested</main><footer>base footer</footer>"},
+		// Other templates are rendered as the content of the default layout
+		{"page.tmpl", "<head>base head</head><main>page Hello</main><footer>base footer</footer>"},
+	}
+	for _, tt := range tests {
+		pageData := PageData{Title: "Hello"}
+		got, err := b.renderPage(tt.template, pageData, pageData)
+		if err != nil {
+			t.Errorf("renderPage(%s) returned an error: %v", tt.template, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("renderPage(%s) mismatch. Got: %q, Want: %q", tt.template, got, tt.want)
+		}
+	}
+
+	// Blocks defined by one page don't leak into the next
+	pageData := PageData{Title: "Again"}
+	got, err := b.renderPage("page.tmpl", pageData, pageData)
+	if err != nil || !strings.HasPrefix(got, "<head>base head</head>") {
+		t.Errorf("renderPage after a child layout mismatch. Got: %q, %v", got, err)
+	}
+}
+
+func TestBuilder_InitTemplatesUnknownBase(t *testing.T) {
+	templateDir := filepath.Join(t.TempDir(), "template")
+	os.MkdirAll(templateDir, 0755)
+	os.WriteFile(filepath.Join(templateDir, "child.tmpl"), []byte(`{{/* extends "missing.tmpl" */}}`), 0644)
+
+	b := Builder{templateDir: templateDir}
+	if err := b.initTemplates(); err == nil || !strings.Contains(err.Error(), "missing.tmpl") {
+		t.Errorf("initTemplates should report the unknown base layout. Got: %v", err)
+	}
+}
+
+func TestBuilder_Partials(t *testing.T) {
+	b := templateBuilder(t, map[string]string{
+		"fullpage.tmpl":         `{{ .Content }}`,
+		"post/single.tmpl":      `single`,
+		"partials/header.tmpl":  `header {{ . }}`,
+		"partials/head.tmpl":    `head {{ . }}`,
+		"partials/nav/top.tmpl": `top`,
+	})
+
+	// Templates are loaded from subdirectories and named by their relative path
+	for _, name := range []string{"post/single.tmpl", "partials/nav/top.tmpl"} {
+		if b.templates.Lookup(name) == nil {
+			t.Errorf("Template %s not loaded", name)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		source  string
+		want    string
+		wantErr bool
+	}{
+		{"by name", `{{ partial "header" "a" }}`, "header a", false},
+		{"with extension", `{{ partial "header.tmpl" "a" }}`, "header a", false},
+		{"with directory", `{{ partial "partials/header.tmpl" "a" }}`, "header a", false},
+		{"nested directory", `{{ partial "nav/top" }}`, "top", false},
+		{"not found", `{{ partial "missing" }}`, "", true},
+		// The output is cached by name and variants, not by the data
+		{"cached", `{{ partialCached "header" "a" }} {{ partialCached "header" "b" }}`, "header a header a", false},
+		{"variants", `{{ partialCached "header" "a" 1 }} {{ partialCached "header" "b" 2 }}`, "header a header b", false},
+		{"variants don't collide with names", `{{ partialCached "head" "a" "er" }} {{ partialCached "header" "b" }}`, "head a header b", false},
+	}
+	for _, tt := range tests {
+		b.partials = make(map[string]template.HTML)
+		tmpl, err := b.templates.Clone()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl.Funcs(b.templateFuncs(tmpl, "en"))
+		if _, err := tmpl.New("test.tmpl").Parse(tt.source); err != nil {
+			t.Fatalf("%s: parse error: %v", tt.name, err)
+		}
+
+		var output bytes.Buffer
+		err = tmpl.ExecuteTemplate(&output, "test.tmpl", nil)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error mismatch. Got: %v, WantErr: %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && output.String() != tt.want {
+			t.Errorf("%s: output mismatch. Got: %q, Want: %q", tt.name, output.String(), tt.want)
+		}
+	}
+}
+
+func TestBuilder_PartialCachedLanguages(t *testing.T) {
+	b := templateBuilder(t, map[string]string{
+		"fullpage.tmpl":     `{{ partialCached "nav" . }}`,
+		"partials/nav.tmpl": `{{ T "home" }}`,
+	})
+	b.translations = map[string]map[string]string{
+		"en": {"home": "Home"},
+		"es": {"home": "Inicio"},
+	}
+
+	// A cached partial is rendered once for each language
+	for _, tt := range []struct{ lang, want string }{{"en", "Home"}, {"es", "Inicio"}, {"en", "Home"}} {
+		pageData := PageData{Page: FileInfo{Language: tt.lang}}
+		got, err := b.renderPage("fullpage.tmpl", pageData, pageData)
+		if err != nil || got != tt.want {
+			t.Errorf("renderPage in %s mismatch. Got: %q, %v, Want: %q", tt.lang, got, err, tt.want)
+		}
+	}
+}
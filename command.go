@@ -2,11 +2,13 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -35,14 +37,19 @@ func (c *Command) Init() string {
 		configFile = filepath.Join(buildCommand.rootPath, ConfigFile)
 	}
 
+	options, err := initCommand.parseFlags(c.Args[1:])
+	if err != nil {
+		logger.Fatal("Invalid init options: %v", err)
+	}
+
 	// Check if the config.yml file already exists
-	if filesystem.Exists(configFile) {
-		logger.Fatal("Warning: The config file exists at %s. Please choose a new root directory.", configFile)
+	if filesystem.Exists(configFile) && !options.Force {
+		logger.Fatal("Warning: The config file exists at %s. Please choose a new root directory or use --force.", configFile)
 	}
 
 	// Create the project files
-	if err := initCommand.CreateNewProjectFiles(buildCommand.rootPath); err != nil {
-		logger.Fatal("Error creating site structure: ", err)
+	if err := initCommand.CreateNewProjectFiles(buildCommand.rootPath, options); err != nil {
+		logger.Fatal("Error creating site structure: %v", err)
 	}
 	return ""
 }
@@ -76,15 +83,89 @@ func (c *Command) Demo() string {
 
 // Builds the Repose site based on the current project default values.
 // It uses command-line flags to modify the root directory and config file.
-// If there is an error parsing the command flags, it prints an error message.
+// Errors are collected and printed by file once the build is done, or the
+// build stops at the first error with --fail-fast.
 func (c *Command) Build(config Config) {
+	flags := flag.NewFlagSet("build", flag.ContinueOnError)
+	flags.BoolVar(&buildCommand.failFast, "fail-fast", false, "Stop the build at the first error")
+	if err := flags.Parse(c.Args[1:]); err != nil {
+		logger.Fatal("Invalid build options: %v", err)
+	}
+
 	logger.Info("Building site from %s with %s", buildCommand.rootPath, ConfigFile)
 	if err := buildCommand.BuildSite(); err != nil {
-		logger.Fatal("Error building site:", err)
+		var buildErrors BuildErrors
+		if errors.As(err, &buildErrors) {
+			buildErrors.Print()
+			logger.Fatal("Build failed with %d errors", len(buildErrors))
+		}
+		logger.Fatal("Error building site: %v", err)
 	}
 	logger.Success("Site built successfully")
 }
 
+// Checks the built site for broken links, missing anchors, missing assets and
+// orphan pages. It exits with a non-zero status when any errors are found.
+func (c *Command) Check(config Config) {
+	options, err := checkCommand.parseFlags(c.Args[1:])
+	if err != nil {
+		logger.Fatal("Invalid check options: %v", err)
+	}
+
+	checkCommand.SetOutputDir(buildCommand.outputDir)
+	if options.Format == "text" {
+		logger.Info("Checking site in %s", buildCommand.outputDir)
+	}
+
+	report, err := checkCommand.CheckSite(options)
+	if err != nil {
+		logger.Fatal("Error checking site: %v", err)
+	}
+	if err := checkCommand.printReport(report); err != nil {
+		logger.Fatal("Error printing report: %v", err)
+	}
+
+	if report.Errors > 0 {
+		os.Exit(1)
+	}
+}
+
+// Lints the front matter of every content file against the content type
+// schemas. It exits with a non-zero status when any errors are found.
+func (c *Command) Lint(config Config) {
+	logger.Info("Linting content in %s", buildCommand.contentDir)
+
+	issues, err := lintCommand.LintContent(buildCommand.contentDir, buildCommand.templateDir)
+	if err != nil {
+		logger.Fatal("Error linting content: %v", err)
+	}
+
+	if errors := lintCommand.printIssues(issues); errors > 0 {
+		os.Exit(1)
+	}
+}
+
+// Moves a content file or bundle, keeping its old URL as an alias and updating
+// the links to it in other content. Use --dry-run to see the changes first.
+func (c *Command) Move(config Config) {
+	options, err := moveCommand.parseFlags(c.Args[1:])
+	if err != nil {
+		logger.Fatal("Invalid mv options: %v", err)
+	}
+
+	changes, err := moveCommand.MoveContent(options)
+	if err != nil {
+		logger.Fatal("Error moving %s: %v", options.Source, err)
+	}
+	moveCommand.printChanges(changes, options.DryRun)
+
+	if options.DryRun {
+		logger.Info("Dry run - no files were changed")
+		return
+	}
+	logger.Success("Moved %s to %s", options.Source, options.Dest)
+}
+
 // Starts serving the Repose site for local preview.
 func (c *Command) Preview(config Config) {
 	logger.Info("Setting up the local preview server")
@@ -96,8 +177,7 @@ func (c *Command) Preview(config Config) {
 	webDir := filepath.Join(buildCommand.rootPath, config.OutputDirectory)
 
 	// Setup the HTTP server.
-	fs := http.FileServer(http.Dir(webDir))
-	http.Handle("/", fs)
+	http.Handle("/", c.previewHandler(webDir))
 
 	// Start the server in a new goroutine so it doesn't block opening the browser.
 	go func() {
@@ -107,14 +187,14 @@ func (c *Command) Preview(config Config) {
 		}
 	}()
 
-	logger.Info("Preview server ready at %s/index.html", config.PreviewURL)
+	logger.Info("Preview server ready at %s/", config.PreviewURL)
 	logger.Detail("Press Ctrl+C to stop the server")
 
 	// Give the server a moment to start.
 	time.Sleep(500 * time.Millisecond)
 
 	// Open the browser.
-	c.openBrowser(config.PreviewURL + "/index.html")
+	c.openBrowser(config.PreviewURL + "/")
 
 	// Keep the server running.
 	select {}
@@ -177,17 +257,11 @@ func (c *Command) createNewContent(config Config, typeDirectory, fileNameParam s
 		contentType = strings.Split(typeDirectory, "/")[0]
 	}
 
-	// Construct the path
+	// Create the content from the archetype for the content type
 	logger.Info("Creating new %s in %s", contentType, config.ContentDirectory)
-	path := filepath.Join(config.ContentDirectory, typeDirectory, fileName)
-
-	// Get default content
-	// @TODO: change thsi to use a yml file for the metadata like default.yml or post.yml
-	content := c.defaultContent(contentType, title)
-
-	// Create the file or directory
-	if err := filesystem.Create(path, content); err != nil {
-		return fmt.Errorf("failed to create %s: %v", path, err)
+	path, err := c.createFromArchetype(config, typeDirectory, contentType, fileName, title)
+	if err != nil {
+		return err
 	}
 
 	logger.Success("Successfully created new %s: %s", contentType, path)
@@ -276,6 +350,24 @@ func (c *Command) promptForInput(prompt, defaultValue string) string {
 	return input
 }
 
+// previewHandler serves the output directory for the preview server.
+// Directories are served by their index.html, so pretty URLs like /about/
+// work as they are. Paths without an extension are served from the matching
+// .html file when it exists, so /about also works for sites with uglyURLs.
+func (c *Command) previewHandler(webDir string) http.Handler {
+	fileServer := http.FileServer(http.Dir(webDir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		urlPath := path.Clean(r.URL.Path)
+		if urlPath != "/" && path.Ext(urlPath) == "" {
+			htmlPath := filepath.Join(webDir, filepath.FromSlash(urlPath)+".html")
+			if info, err := os.Stat(htmlPath); err == nil && !info.IsDir() {
+				r.URL.Path = urlPath + ".html"
+			}
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
+
 // openBrowser tries to open the browser with a given URL.
 func (c *Command) openBrowser(url string) {
 	var err error
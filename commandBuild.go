@@ -8,20 +8,36 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/yuin/goldmark"
 	meta "github.com/yuin/goldmark-meta"
 	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
 )
 
 // Controls the build command
 type Builder struct {
-	rootPath    string
-	contentDir  string
-	outputDir   string
-	templateDir string
-	templates   *template.Template
-	dirsMap     map[string]DirectoryInfo
+	rootPath         string
+	contentDir       string
+	outputDir        string
+	templateDir      string
+	templates        *template.Template
+	layouts          map[string]Layout
+	contentTemplates *template.Template
+	partials         map[string]template.HTML
+	linkIndex        map[string]string
+	brokenRefs       []BuildError
+	dirsMap          map[string]DirectoryInfo
+	sites            map[string]Site               // The data shared by every page, keyed by language
+	translations     map[string]map[string]string  // The translated strings, keyed by language
+	authors          map[string]map[string]*Author // The author profiles, keyed by language and slug
+	bundles          map[string]bool               // The directories that are page bundles, relative to the content directory
+	resources        map[string][]string           // The files copied beside the index page of a directory, keyed by the directory
+	failFast         bool                          // Stop the build at the first error
+	errors           []BuildError                  // The errors collected while building
 }
 
 // Defining a global varaiable for build command
@@ -39,13 +55,20 @@ type DirectoryInfo struct {
 // Holds information about a file during processing
 // Keyed by the full path to the file
 type FileInfo struct {
-	Name        string                 // The name of the file (no extension)
-	Path        string                 // The relative path to the file relative to the content directory
-	OutputPath  string                 // The path and file name for the output file
-	FileType    string                 // The type of file (e.g. "md", "html")
-	ContentType string                 // The type of content (e.g. "page", "post", "project")
-	MetaData    map[string]interface{} // Metadata extracted from the file
-	Content     template.HTML          // The content of the file
+	Name         string                 // The name of the file (no extension)
+	Path         string                 // The relative path to the file relative to the content directory
+	OutputPath   string                 // The permalink of the page, used to write the output file
+	FileType     string                 // The type of file (e.g. "md", "html")
+	ContentType  string                 // The type of content (e.g. "page", "post", "project")
+	MetaData     map[string]interface{} // Metadata extracted from the file
+	Content      template.HTML          // The content of the file
+	Language     string                 // The language of the page (e.g. "en", "es")
+	Date         time.Time              // The date of the page, or the time the file was changed
+	Translations []FileInfo             // The translations of the page into other languages
+	Related      []FileInfo             // The pages that share the most tags and title words with the page
+	Series       *Series                // The series the page is part of, nil when it isn't in one
+	Authors      []*Author              // The profiles of the authors of the page
+	source       string                 // The raw file content, rendered once all files are walked
 }
 
 // PageData holds data to pass into templates
@@ -56,12 +79,35 @@ type PageData struct {
 	Title    string                 // The title of the page
 	Content  template.HTML          // The content of the page
 	Metadata map[string]interface{} // Metadata for the page
+	Page     FileInfo               // The file being rendered (only the path and permalink for index pages)
+	Files    Pages                  // The files listed on an index page
+	Author   *Author                // The author of an author page
+	Site     Site                   // The data shared by every page
+}
+
+// Site holds the data shared by every page, used in templates as .Site
+// Each language of a multilingual site has its own
+type Site struct {
+	Name      string                 // The name of the site
+	URL       string                 // The URL of the site
+	Home      string                 // The home page of the language, e.g. /es/
+	Prefix    string                 // The path the pages of the language are under, e.g. /es
+	Language  string                 // The language of the pages, e.g. es
+	Languages []Language             // Every language of the site, sorted by weight
+	Pages     Pages                  // Every page in the language except index pages, newest first
+	Menus     map[string]Menu        // The navigation menus, e.g. .Site.Menus.main
+	Data      map[string]interface{} // The data files, e.g. .Site.Data.team for data/team.yml
+	Params    map[string]interface{} // The params from the config, e.g. .Site.Params.github
 }
 
 // **********  Public Command Methods  **********
 
 // Generates the site from the content and template files
+// Errors in content files and templates are collected so every page that can
+// be built is built, unless failFast is set to stop at the first error.
 func (b *Builder) BuildSite() error {
+	b.errors = nil
+
 	// Initialize the templates
 	err := b.initTemplates()
 	if err != nil {
@@ -71,7 +117,64 @@ func (b *Builder) BuildSite() error {
 	// Generate the files and dirs for the content directory
 	dirsMap, err := b.walkContentDir()
 	if err != nil {
-		logger.Error("Error walking content directory: ", err)
+		logger.Error("Error walking content directory: %v", err)
+		return err
+	}
+
+	// Load the data files and generate the pages for their records
+	data, err := b.loadData()
+	if err != nil {
+		return err
+	}
+	err = b.generatePages(dirsMap, data)
+	if err != nil {
+		return err
+	}
+
+	// Load the author profiles and link each page to its authors
+	err = b.linkAuthors(dirsMap, data)
+	if err != nil {
+		return err
+	}
+
+	// Load the translated strings and link the translations of each page
+	b.translations, err = b.loadTranslations()
+	if err != nil {
+		return err
+	}
+	b.linkTranslations(dirsMap)
+
+	// Report pages that would be written over each other
+	err = b.checkPermalinks(dirsMap)
+	if err != nil {
+		return err
+	}
+
+	// Build the data shared by every page
+	err = b.buildSiteData(dirsMap, data)
+	if err != nil {
+		return err
+	}
+
+	// Render the markdown now that every file is known, so links between
+	// files can be resolved to their output paths
+	err = b.renderContent(dirsMap)
+	if err != nil {
+		return err
+	}
+
+	// Render the author bios and list their pages
+	err = b.renderAuthors(dirsMap)
+	if err != nil {
+		return err
+	}
+
+	// Find the related pages once the content of each page is rendered
+	b.buildRelated(dirsMap)
+
+	// Link the pages of each series in order
+	err = b.buildSeries(dirsMap)
+	if err != nil {
 		return err
 	}
 
@@ -92,7 +195,47 @@ func (b *Builder) BuildSite() error {
 		return err
 	}
 
-	return nil
+	// Build the archive pages for each year and month
+	err = b.buildArchives(dirsMap)
+	if err != nil {
+		return err
+	}
+
+	// Build the index page of each series
+	err = b.buildSeriesPages(dirsMap)
+	if err != nil {
+		return err
+	}
+
+	// Build the page and feed of each author
+	err = b.buildAuthorPages()
+	if err != nil {
+		return err
+	}
+
+	// Build the redirects from old URLs once every page is written
+	err = b.buildAliases(dirsMap)
+	if err != nil {
+		return err
+	}
+
+	// Build the search index from the rendered content
+	err = b.buildSearchIndex(dirsMap)
+	if err != nil {
+		return err
+	}
+
+	// Build the feeds and sitemap
+	err = b.buildFeeds(dirsMap)
+	if err != nil {
+		return err
+	}
+	err = b.buildSitemap(dirsMap)
+	if err != nil {
+		return err
+	}
+
+	return b.collectedErrors()
 }
 
 // Set the root path and comomon directories for commands
@@ -117,6 +260,11 @@ func (b *Builder) walkContentDir() (map[string]DirectoryInfo, error) {
 	contentPath := filepath.Join(b.rootPath, b.contentDir)
 	logger.Detail("Walking content directory: " + contentPath)
 
+	// Find the page bundles first, so their pages and resources are known as the files are walked
+	if err := b.findBundles(contentPath); err != nil {
+		return nil, err
+	}
+
 	// Walk the content directory and build the files and dirs maps
 	// We update both maps as we walk the directory one time
 	err := filepath.Walk(contentPath, func(path string, info os.FileInfo, err error) error {
@@ -132,15 +280,23 @@ func (b *Builder) walkContentDir() (map[string]DirectoryInfo, error) {
 			return err
 		}
 
+		relPath, _ := filepath.Rel(b.contentDir, path)
 		if isDir {
+			// The page of a bundle is listed with the pages of the directory above it
+			if b.bundleDir(relPath) != "" {
+				return nil
+			}
 			// Process the directory
 			if err := b.processDir(path); err != nil {
 				return err
 			}
+		} else if b.isResource(relPath) {
+			// Resources are copied when their page is rendered
+			return nil
 		} else {
-			// Process the file
+			// Process the file, recording errors so the rest of the site still builds
 			if err := b.processFile(path); err != nil {
-				return err
+				return b.addError(relPath, "", err)
 			}
 		}
 
@@ -178,34 +334,67 @@ func (b *Builder) processDir(path string) error {
 
 // processFile processes a single file, updating the directory information map.
 func (b *Builder) processFile(path string) error {
-	relPath, dir, contentType, fileName, fileType, err := filesystem.GetFileInfo(b.contentDir, path)
+	relPath, dir, _, fileName, fileType, err := filesystem.GetFileInfo(b.contentDir, path)
 	if err != nil {
 		return fmt.Errorf("error getting file info for %q: %v", path, err)
 	}
 
+	// Take the language out of the path, so translations share a section and URL
+	lang, dir, section, fileName := contentLanguage(dir, fileName)
+	isBundle := b.bundles[filepath.Dir(relPath)]
+	if isBundle {
+		dir, fileName = bundlePage(dir)
+	}
+	contentType := section
 	if contentType == "" {
 		contentType = "page"
 	}
 
-	// Process the markdown file to extract HTML content and metadata
-	renderedContent, metaData, err := b.processMarkdown(path)
+	// Read the file and extract the metadata, the content is rendered later
+	source, err := filesystem.Read(path)
+	if err != nil {
+		return fmt.Errorf("error reading markdown file %s: %w", path, err)
+	}
+	metaData, err := b.extractMetadata(source)
 	if err != nil {
-		return fmt.Errorf("error processing markdown for %q: %v", relPath, err)
+		return err
+	}
+
+	// Work out the URL of the page from the permalink pattern and front matter
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	permalink, err := b.permalink(lang, dir, section, fileName, metaData)
+	if err != nil {
+		return err
+	}
+	if isBundle {
+		permalink = bundlePermalink(permalink, metaData)
+	}
+	// Bad dates are reported by the permalink patterns that use them
+	// The modification time is only used to sort pages without a date
+	date, err := pageDate(metaData, info.ModTime())
+	if err != nil {
+		date = info.ModTime()
 	}
 
 	// Create the FileInfo struct
 	fileInfo := FileInfo{
 		Name:        fileName,
 		Path:        relPath,
-		OutputPath:  "/" + filepath.Join(dir, fileName+".html"),
+		OutputPath:  permalink,
 		FileType:    fileType,
 		ContentType: contentType,
 		MetaData:    metaData,
-		Content:     template.HTML(renderedContent),
+		Language:    lang,
+		Date:        date,
+		source:      source,
 	}
 
 	// Update the directory info with the new file
-	dirKey := filepath.Join(b.contentDir, dir)
+	// Pages are listed with the other pages in their language
+	dirKey := filepath.Join(b.contentDir, languageDir(lang), dir)
 	// Process the directory
 	// @TODO: we are processing the directory twice - once here and once in processDir
 	// This is ok for now since we do a check against the map, but can we set this up better?
@@ -235,15 +424,20 @@ func (b *Builder) renderFiles(dirsMap map[string]DirectoryInfo) error {
 	for _, dirInfo := range dirsMap {
 		// Loop through each file in the directory
 		for _, file := range dirInfo.Files {
-			// Remove the "content/" prefix from the file path so we can replace
-			// it with the output directory
-			trimmedPath := strings.TrimPrefix(file.Path, "content/")
-			outputPath := filepath.Join(b.outputDir, trimmedPath)
-			outputPath = strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".html"
+			// Write the file to the path of its permalink
+			outputPath := b.outputFilePath(file.OutputPath)
 
 			// Write the HTML content to the output directory
 			if err := b.renderAndWriteFile(outputPath, file); err != nil {
-				return err
+				if err := b.addError(file.Path, b.templateName(file), err); err != nil {
+					return err
+				}
+			}
+			// Copy the images and other resources beside the page
+			if err := b.copyResources(file); err != nil {
+				if err := b.addError(file.Path, "", err); err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -251,76 +445,181 @@ func (b *Builder) renderFiles(dirsMap map[string]DirectoryInfo) error {
 	return nil
 }
 
-// Process the markdown file and extract metadata
-// @TODO: we have to use this twice - once for markdown and once for HTML, so lets memoize it
-func (b *Builder) processMarkdown(filePath string) (htmlContent string, metaData map[string]interface{}, err error) {
-	// @TODO: see if we need to adjust this for HTML files
-	// @TODO: for html files - what about the metadata?
-	// Create a new markdown parser with the meta extension
-	markdown := goldmark.New(
-		goldmark.WithExtensions(
-			meta.Meta,
-		),
-	)
+// Render the markdown content of every file in the directory map
+// Broken links to other content are reported once all files are rendered
+func (b *Builder) renderContent(dirsMap map[string]DirectoryInfo) error {
+	b.buildLinkIndex(dirsMap)
 
-	// Read the MD file and process it
-	content, err := filesystem.Read(filePath)
-	if err != nil {
-		return "", nil, fmt.Errorf("error reading markdown file %s: %w", filePath, err)
+	for _, dirInfo := range dirsMap {
+		for i, file := range dirInfo.Files {
+			renderedContent, err := b.processMarkdown(file)
+			if err != nil {
+				if err := b.addError(file.Path, "", err); err != nil {
+					return err
+				}
+				continue
+			}
+			dirInfo.Files[i].Content = template.HTML(renderedContent)
+		}
 	}
 
-	// Get the metadata from the markdown file
-	var buf bytes.Buffer
-	context := parser.NewContext()
-	if err := markdown.Convert([]byte(content), &buf, parser.WithContext(context)); err != nil {
-		return "", nil, fmt.Errorf("error converting markdown to HTML: %w", err)
+	return b.reportBrokenRefs()
+}
+
+// Extract the metadata from the front matter of a markdown file
+// YAML front matter is read by the meta extension, TOML and JSON by their parsers
+func (b *Builder) extractMetadata(source string) (map[string]interface{}, error) {
+	if format, frontMatter, _, _ := splitFrontMatter(source); format == frontMatterTOML || format == frontMatterJSON {
+		metaData, err := parseFrontMatter(format, frontMatter)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s metadata: %w", format, err)
+		}
+		return metaData, nil
 	}
 
+	context := parser.NewContext()
+	b.markdown(FileInfo{}).Parser().Parse(text.NewReader([]byte(source)), parser.WithContext(context))
+
 	// Extract metadata with type assertion
-	metaDataMap := meta.Get(context)
+	metaDataMap, err := meta.TryGet(context)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metadata: %w", err)
+	}
 	if metaDataMap == nil {
 		// Handle the case where metadata is not present or not in the expected format
 		metaDataMap = make(map[string]interface{}) // Initialize as empty if not present
 	}
 
-	htmlContent = buf.String()
+	return metaDataMap, nil
+}
+
+// Process the markdown file and render the content to HTML
+// @TODO: we have to use this twice - once for markdown and once for HTML, so lets memoize it
+func (b *Builder) processMarkdown(file FileInfo) (string, error) {
+	// @TODO: see if we need to adjust this for HTML files
+	// @TODO: for html files - what about the metadata?
+	// Translate the strings in shortcodes and render hooks into the page's language
+	if b.contentTemplates != nil {
+		b.contentTemplates.Funcs(b.templateFuncs(b.contentTemplates, file.Language))
+	}
 
-	return htmlContent, metaDataMap, nil
+	// The meta extension strips YAML front matter, other formats are stripped here
+	source := file.source
+	if format, _, _, body := splitFrontMatter(source); format == frontMatterTOML || format == frontMatterJSON {
+		source = body
+	}
+
+	// Swap shortcodes for placeholders so the markdown renderer leaves them alone
+	content, shortcodes, err := b.extractShortcodes(source)
+	if err != nil {
+		return "", err
+	}
+
+	// Convert the markdown to HTML, the meta extension strips the front matter
+	var buf bytes.Buffer
+	if err := b.markdown(file).Convert([]byte(content), &buf); err != nil {
+		return "", fmt.Errorf("error converting markdown to HTML: %w", err)
+	}
+
+	// Render the shortcodes in place of their placeholders
+	return b.renderShortcodes(buf.String(), shortcodes, file)
+}
+
+// Create a new markdown parser with the meta extension and render hooks
+// The render hooks resolve links relative to the given file
+func (b *Builder) markdown(file FileInfo) goldmark.Markdown {
+	hooks := &RenderHooks{builder: b, page: file}
+	markdown := goldmark.New(
+		goldmark.WithExtensions(
+			meta.Meta,
+		),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+		),
+		goldmark.WithRendererOptions(
+			renderer.WithNodeRenderers(util.Prioritized(hooks, renderHooksPriority)),
+		),
+	)
+	hooks.renderer = markdown.Renderer()
+	return markdown
 }
 
 // Render the HTML content with the template and write to the output directory
 func (b *Builder) renderAndWriteFile(outputPath string, file FileInfo) error {
-	// Extract the template name from outputPath or set a default
-	templateFile := file.MetaData["template"].(string)
-	if templateFile == "" {
-		templateFile = "default.tmpl"
-	}
+	templateFile := b.templateName(file)
 
-	// Process the MD content with the template
-	// This will be used to process the full page from the template
-	templateContent, err := b.getTemplateContent(file, templateFile)
-	if err != nil {
-		return err
+	// Use the file name as the title when the metadata doesn't have one
+	title := metaString(file.MetaData, "title")
+	if title == "" {
+		title = file.Name
 	}
 
 	// Build PageData
 	pageData := PageData{
 		SiteName: config.Sitename,
 		Logo:     logo50,
-		Title:    file.MetaData["title"].(string),
-		Content:  template.HTML(templateContent),
+		Title:    title,
+		Content:  file.Content,
 		Metadata: file.MetaData,
+		Page:     file,
+		Site:     b.sites[file.Language],
 	}
 
-	// Execute the full page template with the built PageData
-	var output bytes.Buffer
-	if err := b.templates.ExecuteTemplate(&output, "fullpage.tmpl", pageData); err != nil {
+	// Render the full page from the content template and its base layout
+	output, err := b.renderPage(templateFile, file, pageData)
+	if err != nil {
 		return err
 	}
 
 	// Use filesystem.Create to write the output to the specified path
 	// Assuming filesystem.Create takes a string path and byte slice as content
-	return filesystem.Create(outputPath, output.String())
+	return filesystem.Create(outputPath, output)
+}
+
+// Build the data shared by every page, for each language of the site
+func (b *Builder) buildSiteData(dirsMap map[string]DirectoryInfo, data map[string]interface{}) error {
+	b.sites = make(map[string]Site)
+	languages := siteLanguages()
+	for _, language := range languages {
+		menus, err := b.buildMenus(dirsMap, language.Code)
+		if err != nil {
+			return err
+		}
+
+		name := config.Sitename
+		if languageName := config.Languages[language.Code].Sitename; languageName != "" {
+			name = languageName
+		}
+		var pages Pages
+		for _, file := range sortedFiles(dirsMap) {
+			if file.Language == language.Code && file.Name != "index" {
+				pages = append(pages, file)
+			}
+		}
+
+		b.sites[language.Code] = Site{
+			Name:      name,
+			URL:       config.URL,
+			Home:      language.URL,
+			Prefix:    strings.TrimSuffix("/"+languageDir(language.Code), "/"),
+			Language:  language.Code,
+			Languages: languages,
+			Pages:     pages.ByDate(),
+			Menus:     menus,
+			Data:      data,
+			Params:    config.Params,
+		}
+	}
+	return nil
+}
+
+// Returns the template for a file from its metadata, or the default template
+func (b *Builder) templateName(file FileInfo) string {
+	templateFile := metaString(file.MetaData, "template")
+	if templateFile == "" {
+		templateFile = "default.tmpl"
+	}
+	return templateFile
 }
 
 func (b *Builder) buildIndexFiles(dirsMap map[string]DirectoryInfo) error {
@@ -338,26 +637,25 @@ func (b *Builder) buildIndexFiles(dirsMap map[string]DirectoryInfo) error {
 			}
 			logger.Detail("Building index file for " + contentType + "s")
 
-			// Generate the list content for the index file
-			var listContent bytes.Buffer
-			if err := b.templates.ExecuteTemplate(&listContent, "list.tmpl", dirInfo); err != nil {
-				return err
-			}
-
 			// Build PageData for the full page
+			lang := dirLanguage(dirInfo.Path)
 			pageData := PageData{
 				SiteName: config.Sitename,
 				Logo:     logo50,
 				Title:    "All " + contentType + "s",
-				Content:  template.HTML(listContent.String()),
 				Metadata: dirInfo.Files[0].MetaData,
+				Page:     FileInfo{Path: dirInfo.Path, OutputPath: listPermalink(dirInfo.Path), Language: lang},
+				Files:    dirInfo.Files,
+				Site:     b.sites[lang],
 			}
 
-			// Execute the full page template with the built PageData
-			var output bytes.Buffer
-			if err := b.templates.ExecuteTemplate(&output, "fullpage.tmpl", pageData); err != nil {
-				logger.Error("Error executing template: ", err)
-				return err
+			// Render the list template within its base layout
+			output, err := b.renderPage("list.tmpl", dirInfo, pageData)
+			if err != nil {
+				if err := b.addError(dirInfo.Path, "list.tmpl", err); err != nil {
+					return err
+				}
+				continue
 			}
 
 			// Remove the "content/" prefix from the file path so we can replace
@@ -366,35 +664,16 @@ func (b *Builder) buildIndexFiles(dirsMap map[string]DirectoryInfo) error {
 			outputPath := filepath.Join(b.outputDir, trimmedPath, "index.html")
 			logger.Detail("Writing index file to " + outputPath)
 
-			if err := filesystem.Create(outputPath, output.String()); err != nil {
-				return err
+			if err := filesystem.Create(outputPath, output); err != nil {
+				if err := b.addError(dirInfo.Path, "list.tmpl", err); err != nil {
+					return err
+				}
 			}
 		}
 	}
 	return nil
 }
 
-// Process the content in the pageData struct to generate templated contend
-func (b *Builder) getTemplateContent(file FileInfo, templateFile string) (template.HTML, error) {
-	// Process the template in the metadata with the content in the metadata
-	var tmplContent bytes.Buffer
-	if err := b.templates.ExecuteTemplate(&tmplContent, templateFile, file); err != nil {
-		return "", err
-	}
-
-	return template.HTML(tmplContent.String()), nil
-}
-
-// Parse the templates and store them in a global variable
-func (b *Builder) initTemplates() error {
-	var err error
-	b.templates, err = template.ParseGlob(filepath.Join(b.templateDir, "*.tmpl"))
-	if err != nil {
-		return fmt.Errorf("failed to load templates: %w", err)
-	}
-	return nil
-}
-
 // deletes all files and directories in the output folder except the assets directory.
 // @TODO: this seems way too big and complex - find a better way to do this
 func (b *Builder) resetOutputDirectory() error {
@@ -455,3 +734,35 @@ func (b *Builder) resetOutputDirectory() error {
 
 	return nil
 }
+
+// Returns a metadata value as a string, or an empty string if it is missing
+func metaString(metaData map[string]interface{}, key string) string {
+	value, exists := metaData[key]
+	if !exists || value == nil {
+		return ""
+	}
+	if text, isString := value.(string); isString {
+		return text
+	}
+	return fmt.Sprint(value)
+}
+
+// Returns a metadata value as a list of strings, accepting a single string too
+func metaStrings(metaData map[string]interface{}, key string) []string {
+	switch value := metaData[key].(type) {
+	case string:
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if item != nil && item != "" {
+				values = append(values, fmt.Sprint(item))
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,570 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Controls the check command
+type Checker struct {
+	outputDir string
+	options   CheckOptions
+	pages     map[string]*CheckedPage // Keyed by the site path of the page (e.g. "/post/first.html")
+	inbound   map[string]int          // The number of links to each page from other pages
+	issues    []CheckIssue
+	mutex     sync.Mutex
+}
+
+// Defining a global varaiable for check command
+var checkCommand Checker
+
+// Holds the options for the check command
+type CheckOptions struct {
+	Format    string        // The output format: text or json
+	External  bool          // Whether to check links to other sites
+	Timeout   time.Duration // The timeout for each external request
+	CachePath string        // The file used to cache external link results
+	CacheTTL  time.Duration // How long a cached external result is trusted
+}
+
+// Holds the links, assets and anchors found on a page of the built site
+type CheckedPage struct {
+	Path       string          // The site path of the page
+	Links      []string        // The href of every link on the page
+	Assets     []string        // The src or href of every image, script and stylesheet
+	Anchors    map[string]bool // The ids and anchor names on the page
+	IsRedirect bool            // Whether the page redirects with a meta refresh
+}
+
+// A problem found while checking the built site
+type CheckIssue struct {
+	Kind     string `json:"kind"`     // The kind of problem (see the check constants)
+	Severity string `json:"severity"` // error or warning
+	Page     string `json:"page"`     // The site path of the page with the problem
+	Target   string `json:"target"`   // The link or asset with the problem
+	Message  string `json:"message"`  // A description of the problem
+}
+
+// The results of the check command
+type CheckReport struct {
+	Pages    int          `json:"pages"`
+	Errors   int          `json:"errors"`
+	Warnings int          `json:"warnings"`
+	Issues   []CheckIssue `json:"issues"`
+}
+
+// A cached result for an external link
+type externalResult struct {
+	Status    int       `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// The kinds of problems the checker reports
+const (
+	checkBrokenLink    = "broken-link"
+	checkMissingAnchor = "missing-anchor"
+	checkMissingAsset  = "missing-asset"
+	checkOrphanPage    = "orphan-page"
+	checkExternalLink  = "external-link"
+)
+
+// The severity of problems, only errors fail the check
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// The rel values of the <link> tags that load an asset of the page.
+// Other links, like canonical and alternate, point to pages rather than assets.
+var assetRels = map[string]bool{
+	"stylesheet":       true,
+	"icon":             true,
+	"apple-touch-icon": true,
+	"mask-icon":        true,
+	"manifest":         true,
+	"preload":          true,
+	"modulepreload":    true,
+}
+
+// The number of external links checked at the same time
+const externalWorkers = 8
+
+// Patterns used to pull links, assets and anchors out of the built pages
+var (
+	linkPattern    = regexp.MustCompile(`(?is)<a\s[^>]*?href\s*=\s*["']([^"']*)["']`)
+	assetPattern   = regexp.MustCompile(`(?is)<(?:img|script|source|audio|video|iframe|embed)\s[^>]*?src\s*=\s*["']([^"']*)["']`)
+	linkTagPattern = regexp.MustCompile(`(?is)<link\s[^>]*>`)
+	relPattern     = regexp.MustCompile(`(?is)\srel\s*=\s*["']([^"']*)["']`)
+	hrefPattern    = regexp.MustCompile(`(?is)\shref\s*=\s*["']([^"']*)["']`)
+	anchorPattern  = regexp.MustCompile(`(?is)\s(?:id|name)\s*=\s*["']([^"']+)["']`)
+	refreshPattern = regexp.MustCompile(`(?is)<meta\s[^>]*?http-equiv\s*=\s*["']refresh["']`)
+)
+
+// **********  Public Checker Methods  **********
+
+// Checks the built site in the output directory and returns the report
+func (c *Checker) CheckSite(options CheckOptions) (CheckReport, error) {
+	c.options = options
+	c.pages = make(map[string]*CheckedPage)
+	c.inbound = make(map[string]int)
+	c.issues = nil
+
+	if !filesystem.Exists(c.outputDir) {
+		return CheckReport{}, fmt.Errorf("output directory %s not found - run `repose build` first", c.outputDir)
+	}
+
+	// Collect the links, assets and anchors of every page
+	if err := c.crawlOutputDir(); err != nil {
+		return CheckReport{}, err
+	}
+
+	// Check the internal links and assets, collecting the external links
+	external := make(map[string][]string)
+	for _, pagePath := range c.sortedPages() {
+		page := c.pages[pagePath]
+		for _, link := range page.Links {
+			if target, isExternal := c.externalURL(link); isExternal {
+				external[target] = append(external[target], page.Path)
+				continue
+			}
+			c.checkLink(page, link)
+		}
+		for _, asset := range page.Assets {
+			if target, isExternal := c.externalURL(asset); isExternal {
+				external[target] = append(external[target], page.Path)
+				continue
+			}
+			c.checkAsset(page, asset)
+		}
+	}
+
+	c.checkOrphans()
+
+	if c.options.External {
+		if err := c.checkExternalLinks(external); err != nil {
+			return CheckReport{}, err
+		}
+	}
+
+	return c.report(), nil
+}
+
+// Set the output directory to check
+func (c *Checker) SetOutputDir(outputDir string) {
+	c.outputDir = outputDir
+}
+
+// **********  Private Checker Methods  **********
+
+// Walk the output directory and parse every HTML page
+func (c *Checker) crawlOutputDir() error {
+	return filepath.Walk(c.outputDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(filePath) != ".html" {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(c.outputDir, filePath)
+		if err != nil {
+			return err
+		}
+		content, err := filesystem.Read(filePath)
+		if err != nil {
+			return err
+		}
+
+		page := c.parsePage("/"+filepath.ToSlash(relPath), content)
+		c.pages[page.Path] = page
+		return nil
+	})
+}
+
+// Pull the links, assets and anchors out of the page content
+func (c *Checker) parsePage(pagePath string, content string) *CheckedPage {
+	page := &CheckedPage{
+		Path:    pagePath,
+		Anchors: make(map[string]bool),
+	}
+
+	for _, match := range linkPattern.FindAllStringSubmatch(content, -1) {
+		page.Links = append(page.Links, match[1])
+	}
+	for _, match := range assetPattern.FindAllStringSubmatch(content, -1) {
+		page.Assets = append(page.Assets, match[1])
+	}
+	for _, tag := range linkTagPattern.FindAllString(content, -1) {
+		if href := hrefPattern.FindStringSubmatch(tag); href != nil && isAssetLink(tag) {
+			page.Assets = append(page.Assets, href[1])
+		}
+	}
+	for _, match := range anchorPattern.FindAllStringSubmatch(content, -1) {
+		page.Anchors[match[1]] = true
+	}
+	page.IsRedirect = refreshPattern.MatchString(content)
+
+	return page
+}
+
+// Check that a link points to a page that exists, and to an anchor on it
+func (c *Checker) checkLink(page *CheckedPage, link string) {
+	parsed, skip := c.parseInternalURL(link)
+	if skip {
+		return
+	}
+
+	// Links to an anchor on the same page
+	targetPath := page.Path
+	if parsed.Path != "" {
+		var exists bool
+		targetPath, exists = c.resolvePath(page.Path, parsed.Path)
+		if !exists {
+			c.addIssue(checkBrokenLink, severityError, page.Path, link, "link points to a page that doesn't exist")
+			return
+		}
+		if targetPath != page.Path {
+			c.inbound[targetPath]++
+		}
+	}
+
+	if parsed.Fragment == "" {
+		return
+	}
+	target, isPage := c.pages[targetPath]
+	if isPage && !target.Anchors[parsed.Fragment] {
+		c.addIssue(checkMissingAnchor, severityError, page.Path, link, fmt.Sprintf("anchor #%s not found on %s", parsed.Fragment, targetPath))
+	}
+}
+
+// Check that an image, script or stylesheet exists
+func (c *Checker) checkAsset(page *CheckedPage, asset string) {
+	parsed, skip := c.parseInternalURL(asset)
+	if skip || parsed.Path == "" {
+		return
+	}
+	if _, exists := c.resolvePath(page.Path, parsed.Path); !exists {
+		c.addIssue(checkMissingAsset, severityError, page.Path, asset, "asset doesn't exist")
+	}
+}
+
+// Report pages that no other page links to
+func (c *Checker) checkOrphans() {
+	for _, pagePath := range c.sortedPages() {
+		if pagePath == "/index.html" || pagePath == "/404.html" || c.pages[pagePath].IsRedirect {
+			continue
+		}
+		if c.inbound[pagePath] == 0 {
+			c.addIssue(checkOrphanPage, severityWarning, pagePath, "", "no other page links to this page")
+		}
+	}
+}
+
+// Check the external links, using the cache for recently checked links
+func (c *Checker) checkExternalLinks(external map[string][]string) error {
+	cache := c.loadCache()
+	client := &http.Client{Timeout: c.options.Timeout}
+
+	// Queue the links that aren't cached
+	queue := make(chan string)
+	var wait sync.WaitGroup
+	for i := 0; i < externalWorkers; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for target := range queue {
+				result := c.fetchExternal(client, target)
+				c.mutex.Lock()
+				cache[target] = result
+				c.mutex.Unlock()
+			}
+		}()
+	}
+
+	targets := make([]string, 0, len(external))
+	for target := range external {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	for _, target := range targets {
+		if cached, exists := cache[target]; exists && time.Since(cached.CheckedAt) < c.options.CacheTTL {
+			continue
+		}
+		queue <- target
+	}
+	close(queue)
+	wait.Wait()
+
+	// Report the failures on every page that links to them
+	for _, target := range targets {
+		result := cache[target]
+		if result.Error == "" && result.Status < 400 {
+			continue
+		}
+		message := fmt.Sprintf("returned status %d", result.Status)
+		if result.Error != "" {
+			message = result.Error
+		}
+		for _, pagePath := range external[target] {
+			c.addIssue(checkExternalLink, severityError, pagePath, target, message)
+		}
+	}
+
+	return c.saveCache(cache)
+}
+
+// Request an external link, falling back to GET when HEAD isn't allowed
+func (c *Checker) fetchExternal(client *http.Client, target string) externalResult {
+	result := externalResult{CheckedAt: time.Now()}
+
+	response, err := client.Head(target)
+	if err == nil && (response.StatusCode == http.StatusMethodNotAllowed || response.StatusCode == http.StatusForbidden) {
+		response.Body.Close()
+		response, err = client.Get(target)
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	response.Body.Close()
+	result.Status = response.StatusCode
+
+	return result
+}
+
+// Load the external link cache, starting fresh if it can't be read
+func (c *Checker) loadCache() map[string]externalResult {
+	cache := make(map[string]externalResult)
+	if c.options.CachePath == "" || !filesystem.Exists(c.options.CachePath) {
+		return cache
+	}
+
+	data, err := os.ReadFile(c.options.CachePath)
+	if err != nil {
+		c.warn("Unable to read link cache %s: %v", c.options.CachePath, err)
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		c.warn("Ignoring invalid link cache %s: %v", c.options.CachePath, err)
+		return make(map[string]externalResult)
+	}
+
+	return cache
+}
+
+// Save the external link cache
+func (c *Checker) saveCache(cache map[string]externalResult) error {
+	if c.options.CachePath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.options.CachePath, data, 0644)
+}
+
+// Parse a link, returning skip for links that the checker ignores.
+// Absolute links to the site itself are treated as site paths.
+func (c *Checker) parseInternalURL(link string) (*url.URL, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return nil, true
+	}
+	if (parsed.Scheme == "http" || parsed.Scheme == "https") && !isExternalURL(parsed.String()) {
+		parsed.Scheme = ""
+		parsed.Host = ""
+	}
+	if parsed.Scheme != "" || parsed.Host != "" {
+		return nil, true
+	}
+	return parsed, false
+}
+
+// Returns the absolute URL of a link to another site
+func (c *Checker) externalURL(link string) (string, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return "", false
+	}
+
+	// Links to the site itself are checked as internal links
+	if !isExternalURL(parsed.String()) {
+		return "", false
+	}
+
+	parsed.Fragment = ""
+	return parsed.String(), true
+}
+
+// Resolve a link path on a page to a file in the output directory.
+// Returns the site path of the file and whether it exists.
+func (c *Checker) resolvePath(pagePath string, linkPath string) (string, bool) {
+	if !strings.HasPrefix(linkPath, "/") {
+		linkPath = path.Join(path.Dir(pagePath), linkPath)
+	}
+	if unescaped, err := url.PathUnescape(linkPath); err == nil {
+		linkPath = unescaped
+	}
+	isDir := strings.HasSuffix(linkPath, "/")
+	linkPath = path.Clean(linkPath)
+
+	filePath := filepath.Join(c.outputDir, filepath.FromSlash(linkPath))
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return linkPath, false
+	}
+
+	// Directories are served by their index file
+	if info.IsDir() || isDir {
+		linkPath = path.Join(linkPath, "index.html")
+		return linkPath, filesystem.Exists(filepath.Join(c.outputDir, filepath.FromSlash(linkPath)))
+	}
+
+	return linkPath, true
+}
+
+// Record a problem found while checking
+func (c *Checker) addIssue(kind string, severity string, pagePath string, target string, message string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.issues = append(c.issues, CheckIssue{
+		Kind:     kind,
+		Severity: severity,
+		Page:     pagePath,
+		Target:   target,
+		Message:  message,
+	})
+}
+
+// Build the report from the issues, sorted so the output is stable
+func (c *Checker) report() CheckReport {
+	sort.SliceStable(c.issues, func(i, j int) bool {
+		if c.issues[i].Kind != c.issues[j].Kind {
+			return c.issues[i].Kind < c.issues[j].Kind
+		}
+		if c.issues[i].Page != c.issues[j].Page {
+			return c.issues[i].Page < c.issues[j].Page
+		}
+		return c.issues[i].Target < c.issues[j].Target
+	})
+
+	report := CheckReport{Pages: len(c.pages), Issues: c.issues}
+	for _, issue := range c.issues {
+		if issue.Severity == severityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	if report.Issues == nil {
+		report.Issues = []CheckIssue{}
+	}
+
+	return report
+}
+
+// Returns the site paths of the pages in a stable order
+func (c *Checker) sortedPages() []string {
+	paths := make([]string, 0, len(c.pages))
+	for pagePath := range c.pages {
+		paths = append(paths, pagePath)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Print a warning about the check itself. With the JSON format the warning
+// goes to stderr, so stdout only holds the report.
+func (c *Checker) warn(message string, value ...any) {
+	if c.options.Format == "json" {
+		fmt.Fprintf(os.Stderr, "Warning: "+message+"\n", value...)
+		return
+	}
+	logger.Warn(message, value...)
+}
+
+// Print the report in the requested format
+func (c *Checker) printReport(report CheckReport) error {
+	if c.options.Format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	kind := ""
+	for _, issue := range report.Issues {
+		if issue.Kind != kind {
+			kind = issue.Kind
+			logger.Info("%s:", kind)
+		}
+		target := ""
+		if issue.Target != "" {
+			target = " -> " + issue.Target
+		}
+		if issue.Severity == severityError {
+			logger.Error("%s%s: %s", issue.Page, target, issue.Message)
+		} else {
+			logger.Warn("%s%s: %s", issue.Page, target, issue.Message)
+		}
+	}
+
+	summary := fmt.Sprintf("Checked %d pages: %d errors, %d warnings", report.Pages, report.Errors, report.Warnings)
+	if report.Errors > 0 {
+		logger.Error(summary)
+	} else {
+		logger.Success(summary)
+	}
+	return nil
+}
+
+// Parse the flags for the check command
+func (c *Checker) parseFlags(args []string) (CheckOptions, error) {
+	options := CheckOptions{}
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.StringVar(&options.Format, "format", "text", "Output format: text or json")
+	flags.BoolVar(&options.External, "external", false, "Also check links to other sites")
+	flags.DurationVar(&options.Timeout, "timeout", 10*time.Second, "Timeout for each external link")
+	flags.StringVar(&options.CachePath, "cache", filepath.Join(buildCommand.rootPath, ".repose-linkcache.json"), "File used to cache external link results")
+	flags.DurationVar(&options.CacheTTL, "cache-ttl", 24*time.Hour, "How long cached external link results are trusted")
+
+	if err := flags.Parse(args); err != nil {
+		return options, err
+	}
+	if options.Format != "text" && options.Format != "json" {
+		return options, fmt.Errorf("unknown format %q - use text or json", options.Format)
+	}
+
+	return options, nil
+}
+
+// Check if a <link> tag loads an asset, from its rel attribute (e.g. "shortcut icon")
+func isAssetLink(tag string) bool {
+	rel := relPattern.FindStringSubmatch(tag)
+	if rel == nil {
+		return false
+	}
+	for _, value := range strings.Fields(strings.ToLower(rel[1])) {
+		if assetRels[value] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChecker_CheckSite(t *testing.T) {
+	// Build a small site with one of each problem
+	outputDir := t.TempDir()
+	pages := map[string]string{
+		"index.html": `<a href="/post/">Posts</a> <a href="/post/one.html#intro">One</a> <img src="/img/missing.png">` +
+			`<link rel="canonical" href="https://example.com/"><link rel="alternate" href="/missing.xml"><link href="/favicon.ico" rel="shortcut icon">`,
+		"post/index.html": `<a href="one.html#nope">One</a> <a href="two.html">Two</a>`,
+		"post/one.html":   `<h2 id="intro">Intro</h2> <a href="#intro">Top</a> <link rel="stylesheet" href="/css/site.css">`,
+		"orphan.html":     `<p>Nobody links here</p>`,
+		"css/site.css":    `body {}`,
+		"favicon.ico":     ``,
+	}
+	for name, content := range pages {
+		filePath := filepath.Join(outputDir, name)
+		os.MkdirAll(filepath.Dir(filePath), 0755)
+		os.WriteFile(filePath, []byte(content), 0644)
+	}
+
+	var checker Checker
+	checker.SetOutputDir(outputDir)
+	report, err := checker.CheckSite(CheckOptions{Format: "text"})
+	if err != nil {
+		t.Fatalf("Failed to check site: %s", err)
+	}
+
+	want := map[string]string{
+		checkBrokenLink:    "/post/index.html",
+		checkMissingAnchor: "/post/index.html",
+		checkMissingAsset:  "/index.html",
+		checkOrphanPage:    "/orphan.html",
+	}
+	if len(report.Issues) != len(want) {
+		t.Fatalf("Issue count mismatch. Got: %d, Want: %d (%v)", len(report.Issues), len(want), report.Issues)
+	}
+	for _, issue := range report.Issues {
+		if want[issue.Kind] != issue.Page {
+			t.Errorf("Unexpected %s issue on %s", issue.Kind, issue.Page)
+		}
+	}
+	if report.Errors != 3 || report.Warnings != 1 {
+		t.Errorf("Severity count mismatch. Got: %d errors, %d warnings", report.Errors, report.Warnings)
+	}
+
+	// Only the <link> tags that load an asset are checked as assets
+	if assets := checker.pages["/index.html"].Assets; len(assets) != 2 || assets[1] != "/favicon.ico" {
+		t.Errorf("Asset mismatch. Got: %v, Want: [/img/missing.png /favicon.ico]", assets)
+	}
+}
+
+func TestChecker_LoadCacheJSON(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	os.WriteFile(cachePath, []byte("not json"), 0644)
+
+	// Capture stdout, which only holds the report with the JSON format
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = writer
+	checker := Checker{options: CheckOptions{Format: "json", CachePath: cachePath}}
+	cache := checker.loadCache()
+	writer.Close()
+	os.Stdout = stdout
+
+	output, _ := io.ReadAll(reader)
+	if len(cache) != 0 || len(output) != 0 {
+		t.Errorf("Expected an empty cache and no output on stdout. Got: %v, %q", cache, output)
+	}
+}
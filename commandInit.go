@@ -1,8 +1,17 @@
 package main
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 )
 
@@ -16,36 +25,66 @@ type FileContent struct {
 	Content string
 }
 
+// InitOptions holds the values for a new project, from the flags or prompts
+type InitOptions struct {
+	Sitename string // The name of the site
+	Author   string // The author of the site
+	Theme    string // The CSS theme: pico, bootstrap, tailwind or none
+	URL      string // The URL of the site
+	Editor   string // The editor used by repose new, none for no editor
+	Yes      bool   // Use the defaults for the values without a flag instead of prompting
+	Force    bool   // Replace the files of an existing project
+	From     string // A starter directory or tarball copied over the new project
+}
+
+// The themes a new project can use
+var initThemes = []string{"pico", "bootstrap", "tailwind", "none"}
+
 // **********  Public Command Methods  **********
 
 // Creates the default files and directories for a new project.
-func (i *Init) CreateNewProjectFiles(rootPath string) error {
-	// Create the config file
-	if err := config.Create(rootPath); err != nil {
-		return err
+// The project is written to a temporary directory first and then copied into
+// place, so a failed init doesn't leave a half created project behind.
+func (i *Init) CreateNewProjectFiles(rootPath string, options InitOptions) error {
+	if rootPath == "" {
+		rootPath = "."
 	}
 
 	// Set the output for the root path
 	installDir := rootPath
-	if rootPath == "" || rootPath == "." {
+	if rootPath == "." {
 		installDir = "this directory"
 	}
 
+	stageDir, err := os.MkdirTemp("", "repose-init-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stageDir)
+
+	// Create the config file
+	if err := config.Create(stageDir, options); err != nil {
+		return err
+	}
+
 	// Create the project directory structure
 	logger.Info("Creating new project in %s", installDir)
 	logger.Detail("Creating directory structure...")
 	dirs := []string{"content", "template", "web", "web/assets", "web/assets/css", "web/assets/js", "web/assets/img"}
 	for _, dir := range dirs {
-		dirPath := filepath.Join(rootPath, dir)
-		if _, err := os.Stat(dirPath); os.IsNotExist(err) {
-			if err := os.Mkdir(dirPath, 0755); err != nil {
-				return err
-			}
+		if err := os.MkdirAll(filepath.Join(stageDir, dir), 0755); err != nil {
+			return err
 		}
 	}
 
 	// Load the new config file
-	config, _ = config.Load()
+	data, err := os.ReadFile(filepath.Join(stageDir, ConfigFile))
+	if err != nil {
+		return err
+	}
+	if config, err = config.parse(data); err != nil {
+		return err
+	}
 
 	// Get the template constants and files names
 	files := i.getTemplateContents(config)
@@ -53,13 +92,25 @@ func (i *Init) CreateNewProjectFiles(rootPath string) error {
 
 	// Loop over the files and create them
 	for _, f := range files {
-		filePath := filepath.Join(rootPath, f.Name)
+		filePath := filepath.Join(stageDir, f.Name)
 		cleanContent := strings.TrimSpace(f.Content)
 		if err := filesystem.Create(filePath, cleanContent); err != nil {
 			return err
 		}
 	}
 
+	// Copy the starter over the default files
+	if options.From != "" {
+		logger.Detail("Copying starter from %s...", options.From)
+		if err := i.copyStarter(options.From, stageDir); err != nil {
+			return fmt.Errorf("error copying starter %s: %w", options.From, err)
+		}
+	}
+
+	if err := i.installProject(stageDir, rootPath, options.Force); err != nil {
+		return err
+	}
+
 	logger.Success("Repose project created in %s", installDir)
 
 	return nil
@@ -126,10 +177,297 @@ func (i *Init) getTemplateContents(config Config) []FileContent {
 		{"template/footer.tmpl", themeTemplates["footer"]},
 		{"template/list.tmpl", themeTemplates["list"]},
 		{"template/listitem.tmpl", themeTemplates["listitem"]},
+		{"template/archive.tmpl", ArchiveTemplate},
+		{"template/author.tmpl", AuthorTemplate},
+		{"archetypes/default.md", DefaultArchetype},
 		{"content/index.md", indexMD},
 		{"content/test.md", MarkdownTest},
 		{"web/asset/css/styles.css", themeTemplates["css"]},
 	}
 
+	// The search page and the script it loads come with the themes, a project
+	// without a theme starts without them
+	if config.Theme != "none" {
+		files = append(files,
+			FileContent{"template/search.tmpl", SearchTemplate},
+			FileContent{"content/search.md", SearchMD},
+			FileContent{"web/assets/js/search.js", SearchJS},
+		)
+	}
+
 	return files
 }
+
+// Parse the flags for the init command.
+// Values without a flag are prompted for, or use the defaults with --yes or
+// when stdin isn't a terminal (e.g. in CI).
+func (i *Init) parseFlags(args []string) (InitOptions, error) {
+	options := InitOptions{}
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	flags.StringVar(&options.Sitename, "sitename", "", "The name of the site")
+	flags.StringVar(&options.Author, "author", "", "The author of the site")
+	flags.StringVar(&options.Theme, "theme", "", "The CSS theme: pico, bootstrap, tailwind or none")
+	flags.StringVar(&options.URL, "url", "", "The URL of the site")
+	flags.StringVar(&options.Editor, "editor", "", "The editor used by repose new, none for no editor")
+	flags.BoolVar(&options.Yes, "yes", false, "Use the defaults for the values without a flag")
+	flags.BoolVar(&options.Yes, "y", false, "Use the defaults for the values without a flag (shorthand)")
+	flags.BoolVar(&options.Force, "force", false, "Replace the files of an existing project")
+	flags.StringVar(&options.From, "from", "", "A starter directory or tarball to copy over the new project")
+
+	if err := flags.Parse(args); err != nil {
+		return options, err
+	}
+	if flags.NArg() > 0 {
+		return options, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	interactive := !options.Yes && isTerminal(os.Stdin)
+	prompts := []struct {
+		value        *string
+		prompt       string
+		defaultValue string
+	}{
+		{&options.Sitename, "Enter the site name", "Repose site"},
+		{&options.Author, "Enter the author's name", "Creator"},
+		{&options.Editor, "Enter the editor ('none' for no editing)", "nano"},
+		{&options.URL, "Enter the site URL", "mysite.com"},
+		{&options.Theme, "Enter the CSS theme to use (pico, bootstrap, tailwind, none)", "pico"},
+	}
+	for _, prompt := range prompts {
+		if *prompt.value != "" {
+			continue
+		}
+		*prompt.value = prompt.defaultValue
+		if interactive {
+			*prompt.value = command.promptForInput(prompt.prompt, prompt.defaultValue)
+		}
+	}
+
+	if !slices.Contains(initThemes, options.Theme) {
+		return options, fmt.Errorf("unknown theme %q - use %s", options.Theme, strings.Join(initThemes, ", "))
+	}
+	if options.From != "" && !filesystem.Exists(options.From) {
+		return options, fmt.Errorf("starter %s not found", options.From)
+	}
+
+	return options, nil
+}
+
+// Copy the files of a starter directory or tarball (.tar, .tar.gz or .tgz) into the project
+func (i *Init) copyStarter(from string, stageDir string) error {
+	info, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return filepath.Walk(from, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			relPath, err := filepath.Rel(from, path)
+			if err != nil {
+				return err
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return writeProjectFile(filepath.Join(stageDir, relPath), content)
+		})
+	}
+
+	file, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(from, ".gz") || strings.HasSuffix(from, ".tgz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	// Read the files first, so a shared top level directory can be dropped
+	files := make(map[string][]byte)
+	archive := tar.NewReader(reader)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path %s in starter", header.Name)
+		}
+		content, err := io.ReadAll(archive)
+		if err != nil {
+			return err
+		}
+		files[name] = content
+	}
+
+	prefix := starterPrefix(files)
+	for name, content := range files {
+		if err := writeProjectFile(filepath.Join(stageDir, filepath.FromSlash(strings.TrimPrefix(name, prefix))), content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Copy the staged project into the root directory. Existing files are only
+// replaced with force, after a copy is kept in the staging directory. When a
+// copy fails, the replaced files are restored and the files and directories
+// created so far are removed again.
+func (i *Init) installProject(stageDir string, rootPath string, force bool) error {
+	var sources []string
+	err := filepath.Walk(stageDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == stageDir {
+			return err
+		}
+		sources = append(sources, path)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Check for existing files before anything is written
+	if !force {
+		for _, source := range sources {
+			relPath, _ := filepath.Rel(stageDir, source)
+			if info, err := os.Stat(filepath.Join(rootPath, relPath)); err == nil && !info.IsDir() {
+				return fmt.Errorf("%s already exists - use --force to replace it", filepath.Join(rootPath, relPath))
+			}
+		}
+	}
+
+	backupDir := filepath.Join(stageDir, ".repose-backup")
+	var created []string
+	replaced := make(map[string]string) // The backup of each replaced file, keyed by the file
+	for _, source := range sources {
+		relPath, _ := filepath.Rel(stageDir, source)
+		target := filepath.Join(rootPath, relPath)
+		if err = backupProjectFile(target, filepath.Join(backupDir, relPath), replaced); err != nil {
+			break
+		}
+		if err = copyProjectPath(source, target, &created); err != nil {
+			break
+		}
+	}
+	if err != nil {
+		for target, backup := range replaced {
+			restoreProjectFile(backup, target)
+		}
+		// Remove the files before the directories that hold them
+		sort.Sort(sort.Reverse(sort.StringSlice(created)))
+		for _, path := range created {
+			os.Remove(path)
+		}
+		return fmt.Errorf("error creating project files: %w", err)
+	}
+	return nil
+}
+
+// Copy a staged file or directory to the project, recording the new paths
+func copyProjectPath(source string, target string, created *[]string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	_, statErr := os.Stat(target)
+	isNew := errors.Is(statErr, os.ErrNotExist)
+
+	if info.IsDir() {
+		if !isNew {
+			return nil
+		}
+		if err := os.Mkdir(target, 0755); err != nil {
+			return err
+		}
+		*created = append(*created, target)
+		return nil
+	}
+
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(target, content, 0644); err != nil {
+		return err
+	}
+	if isNew {
+		*created = append(*created, target)
+	}
+	return nil
+}
+
+// Copy a file that is about to be replaced into the backup directory
+func backupProjectFile(target string, backup string, replaced map[string]string) error {
+	info, err := os.Stat(target)
+	if err != nil || info.IsDir() {
+		return nil
+	}
+	content, err := os.ReadFile(target)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(backup), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(backup, content, info.Mode().Perm()); err != nil {
+		return err
+	}
+	replaced[target] = backup
+	return nil
+}
+
+// Put a replaced file back from its backup, with its permissions
+func restoreProjectFile(backup string, target string) {
+	info, err := os.Stat(backup)
+	if err != nil {
+		return
+	}
+	if content, err := os.ReadFile(backup); err == nil {
+		os.WriteFile(target, content, info.Mode().Perm())
+	}
+}
+
+// Write a file of the project, creating its directory
+func writeProjectFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// Returns the top level directory shared by every file in a starter tarball,
+// e.g. "starter-main/", or an empty string when they don't share one
+func starterPrefix(files map[string][]byte) string {
+	prefix := ""
+	for name := range files {
+		first, _, found := strings.Cut(name, "/")
+		if !found || (prefix != "" && prefix != first+"/") {
+			return ""
+		}
+		prefix = first + "/"
+	}
+	return prefix
+}
+
+// Check if a file is a terminal, so init only prompts when someone can answer
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
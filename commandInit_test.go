@@ -0,0 +1,182 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInit_ParseFlags(t *testing.T) {
+	options, err := initCommand.parseFlags([]string{"--sitename", "My site", "--theme", "bootstrap", "--yes"})
+	if err != nil {
+		t.Fatalf("parseFlags failed: %v", err)
+	}
+	want := InitOptions{Sitename: "My site", Author: "Creator", Theme: "bootstrap", URL: "mysite.com", Editor: "nano", Yes: true}
+	if options != want {
+		t.Errorf("parseFlags mismatch. Got: %+v, Want: %+v", options, want)
+	}
+
+	for _, args := range [][]string{
+		{"--yes", "--theme", "purple"},
+		{"--yes", "--from", filepath.Join(t.TempDir(), "missing")},
+		{"--yes", "extra"},
+	} {
+		if _, err := initCommand.parseFlags(args); err == nil {
+			t.Errorf("parseFlags(%v) should fail", args)
+		}
+	}
+}
+
+func TestInit_CreateNewProjectFiles(t *testing.T) {
+	restoreConfig(t)
+
+	// A starter directory overrides the default files and adds its own
+	starter := t.TempDir()
+	writeTestFile(t, filepath.Join(starter, "content", "index.md"), "# Starter home")
+	writeTestFile(t, filepath.Join(starter, "data", "links.yml"), "- home")
+
+	root := t.TempDir()
+	options := InitOptions{Sitename: "Scripted", Author: "Ron", Theme: "none", URL: "example.com", Editor: "none", From: starter}
+	if err := initCommand.CreateNewProjectFiles(root, options); err != nil {
+		t.Fatalf("CreateNewProjectFiles failed: %v", err)
+	}
+
+	configFile := readTestFile(t, filepath.Join(root, ConfigFile))
+	if !strings.Contains(configFile, "sitename: Scripted") || !strings.Contains(configFile, "theme: none") {
+		t.Errorf("config.yml doesn't use the options:\n%s", configFile)
+	}
+	if got := readTestFile(t, filepath.Join(root, "content", "index.md")); got != "# Starter home" {
+		t.Errorf("starter content mismatch. Got: %q", got)
+	}
+	for _, file := range []string{"data/links.yml", "template/default.tmpl", "web/assets/css"} {
+		if _, err := os.Stat(filepath.Join(root, file)); err != nil {
+			t.Errorf("%s should exist: %v", file, err)
+		}
+	}
+	// Without a theme there is no search page or the script it loads
+	for _, file := range []string{"template/search.tmpl", "content/search.md", "web/assets/js/search.js"} {
+		if _, err := os.Stat(filepath.Join(root, file)); !os.IsNotExist(err) {
+			t.Errorf("%s shouldn't exist without a theme", file)
+		}
+	}
+
+	// Existing files are only replaced with force
+	if err := initCommand.CreateNewProjectFiles(root, options); err == nil {
+		t.Errorf("CreateNewProjectFiles should fail when the project exists")
+	}
+	options.Force = true
+	options.From = ""
+	if err := initCommand.CreateNewProjectFiles(root, options); err != nil {
+		t.Fatalf("CreateNewProjectFiles with force failed: %v", err)
+	}
+	if got := readTestFile(t, filepath.Join(root, "content", "index.md")); got == "# Starter home" {
+		t.Errorf("content/index.md should be replaced with force")
+	}
+}
+
+func TestInit_CreateFromTarball(t *testing.T) {
+	restoreConfig(t)
+
+	tarball := filepath.Join(t.TempDir(), "starter.tar.gz")
+	writeTestTarball(t, tarball, map[string]string{
+		"starter-main/config.yml":       "sitename: From tarball\ntheme: none\n",
+		"starter-main/content/about.md": "# About",
+	})
+
+	root := t.TempDir()
+	options := InitOptions{Sitename: "Scripted", Author: "Ron", Theme: "pico", URL: "example.com", Editor: "none", From: tarball}
+	if err := initCommand.CreateNewProjectFiles(root, options); err != nil {
+		t.Fatalf("CreateNewProjectFiles failed: %v", err)
+	}
+	if got := readTestFile(t, filepath.Join(root, ConfigFile)); !strings.HasPrefix(got, "sitename: From tarball") {
+		t.Errorf("the starter config.yml should replace the default. Got: %q", got)
+	}
+	if got := readTestFile(t, filepath.Join(root, "content", "about.md")); got != "# About" {
+		t.Errorf("starter content mismatch. Got: %q", got)
+	}
+
+	// A tarball escaping the project fails without leaving files behind
+	bad := filepath.Join(t.TempDir(), "bad.tar")
+	writeTestTarball(t, bad, map[string]string{"../outside.md": "# Outside"})
+	empty := t.TempDir()
+	options.From = bad
+	if err := initCommand.CreateNewProjectFiles(empty, options); err == nil {
+		t.Errorf("CreateNewProjectFiles should fail for a path outside the project")
+	}
+	if entries, _ := os.ReadDir(empty); len(entries) > 0 {
+		t.Errorf("a failed init should leave the directory empty, found %d entries", len(entries))
+	}
+}
+
+func TestInit_InstallProjectRollback(t *testing.T) {
+	stage := t.TempDir()
+	writeTestFile(t, filepath.Join(stage, "a", "one.md"), "one")
+	writeTestFile(t, filepath.Join(stage, "b", "two.md"), "two")
+	writeTestFile(t, filepath.Join(stage, "README.md"), "New readme")
+
+	// A file where the project needs a directory makes the copy fail part way
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "b"), "not a directory")
+	writeTestFile(t, filepath.Join(root, "README.md"), "My readme")
+	if err := initCommand.installProject(stage, root, true); err == nil {
+		t.Fatalf("installProject should fail")
+	}
+	if _, err := os.Stat(filepath.Join(root, "a")); !os.IsNotExist(err) {
+		t.Errorf("the directories created before the failure should be removed")
+	}
+	if got := readTestFile(t, filepath.Join(root, "b")); got != "not a directory" {
+		t.Errorf("existing files should be kept. Got: %q", got)
+	}
+	// Files replaced with force before the failure are restored
+	if got := readTestFile(t, filepath.Join(root, "README.md")); got != "My readme" {
+		t.Errorf("replaced files should be restored. Got: %q", got)
+	}
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := writeProjectFile(path, []byte(content)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func writeTestTarball(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var archive *tar.Writer
+	if strings.HasSuffix(path, ".gz") {
+		gzipWriter := gzip.NewWriter(file)
+		defer gzipWriter.Close()
+		archive = tar.NewWriter(gzipWriter)
+	} else {
+		archive = tar.NewWriter(file)
+	}
+	defer archive.Close()
+
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := archive.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := archive.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,454 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Controls the lint command
+type Linter struct {
+	contentDir  string
+	templateDir string
+	templates   map[string]bool     // The names of the templates that exist
+	slugs       map[string][]string // The files using each slug, keyed by language directory, directory and slug
+	issues      []LintIssue
+}
+
+// Defining a global varaiable for lint command
+var lintCommand Linter
+
+// A problem found in a content file
+type LintIssue struct {
+	File     string // The path of the content file
+	Line     int    // The line of the problem (1 for problems with the whole file)
+	Severity string // error or warning
+	Message  string // A description of the problem
+}
+
+// ContentSchema describes the front matter allowed for a content type
+type ContentSchema struct {
+	// Strict reports keys that aren't listed in the fields
+	Strict bool `yaml:"strict"`
+	// Fields holds the schema for each front matter key
+	Fields map[string]FieldSchema `yaml:"fields"`
+}
+
+// FieldSchema describes a single front matter key
+type FieldSchema struct {
+	// Type is the expected type: string, bool, number, list, map or date
+	Type string `yaml:"type"`
+	// Required keys must be present and not empty
+	Required bool `yaml:"required"`
+	// Values lists the allowed values, leave empty to allow any value
+	Values []string `yaml:"values"`
+	// Format is the Go time layout for date fields - defaults to 2006-01-02
+	Format string `yaml:"format"`
+}
+
+// The schema used for content types without a schema in the config
+// It matches the front matter created by `repose new`
+var defaultContentSchema = ContentSchema{
+	Strict: true,
+	Fields: map[string]FieldSchema{
+		"title":          {Type: "string", Required: true},
+		"description":    {Type: "string"},
+		"tags":           {Type: "list"},
+		"categories":     {Type: "list"},
+		"image":          {Type: "string"},
+		"index":          {Type: "bool"},
+		"publish":        {Type: "bool"},
+		"author":         {},
+		"publish_date":   {Type: "date"},
+		"date":           {Type: "date"},
+		"template":       {Type: "string"},
+		"slug":           {Type: "string"},
+		"url":            {Type: "string"},
+		"canonical":      {Type: "string"},
+		"translationKey": {Type: "string"},
+		"aliases":        {Type: "list"},
+		"menu":           {},
+		"series":         {Type: "string"},
+		"series_order":   {Type: "number"},
+	},
+}
+
+// The default layout for date fields
+const defaultDateFormat = "2006-01-02"
+
+// Matches the line number in a YAML error message
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// **********  Public Linter Methods  **********
+
+// Lints every content file and returns the problems found
+func (l *Linter) LintContent(contentDir string, templateDir string) ([]LintIssue, error) {
+	l.contentDir = contentDir
+	l.templateDir = templateDir
+	l.slugs = make(map[string][]string)
+	l.issues = nil
+
+	templates, err := l.loadTemplateNames()
+	if err != nil {
+		return nil, err
+	}
+	l.templates = templates
+
+	err = filepath.Walk(contentDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// Only pages have front matter, resources such as images are skipped
+		if info.IsDir() || !isPageFile(path) {
+			return nil
+		}
+		return l.lintFile(path)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	l.checkDuplicateSlugs()
+	l.sortIssues()
+
+	return l.issues, nil
+}
+
+// **********  Private Linter Methods  **********
+
+// Lint the front matter of a single content file
+func (l *Linter) lintFile(path string) error {
+	relPath, err := filepath.Rel(l.contentDir, path)
+	if err != nil {
+		return err
+	}
+	content, err := filesystem.Read(path)
+	if err != nil {
+		return err
+	}
+
+	format, frontMatter, startLine, _ := splitFrontMatter(content)
+	if format == "" {
+		l.addIssue(path, 1, severityError, "missing front matter")
+		return nil
+	}
+
+	// Parse the front matter, keeping the keys in order
+	items, err := l.parseFrontMatter(format, frontMatter)
+	if err != nil {
+		line := startLine
+		if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+			offset, _ := strconv.Atoi(match[1])
+			line += offset - 1
+		}
+		l.addIssue(path, line, severityError, "invalid front matter: "+err.Error())
+		return nil
+	}
+	keyLines := l.keyLines(format, frontMatter, startLine)
+
+	// Check the values against the schema for the content type
+	lang, dir, contentType, fileName := l.contentInfo(relPath)
+	schema := l.schemaFor(contentType)
+	metaData := make(map[string]interface{})
+	for _, item := range items {
+		key := fmt.Sprint(item.Key)
+		metaData[key] = item.Value
+
+		field, known := schema.Fields[key]
+		if !known {
+			if schema.Strict {
+				l.addIssue(path, keyLines[key], severityWarning, fmt.Sprintf("unknown key %q for %s content", key, contentType))
+			}
+			continue
+		}
+		if message := l.checkField(field, item.Value); message != "" {
+			l.addIssue(path, keyLines[key], severityError, fmt.Sprintf("%s %s", key, message))
+		}
+	}
+
+	// Check the required keys are present
+	for _, key := range sortedKeys(schema.Fields) {
+		if _, exists := metaData[key]; !exists && schema.Fields[key].Required {
+			l.addIssue(path, startLine-1, severityError, fmt.Sprintf("missing required key %q", key))
+		}
+	}
+
+	// Titles are used for the page title, so they can't be empty even when
+	// the schema doesn't require them
+	title, hasTitle := metaData["title"]
+	if hasTitle && !schema.Fields["title"].Required && strings.TrimSpace(fmt.Sprint(valueOrEmpty(title))) == "" {
+		l.addIssue(path, keyLines["title"], severityError, "title is empty")
+	}
+
+	// Templates must exist or the build will fail
+	if templateName, exists := metaData["template"]; exists && valueOrEmpty(templateName) != "" {
+		name := fmt.Sprint(templateName)
+		if !l.templates[name] {
+			l.addIssue(path, keyLines["template"], severityError, fmt.Sprintf("unknown template %q", name))
+		}
+	}
+
+	// Collect the slug so duplicates can be reported once every file is read
+	slug := fileName
+	if value, exists := metaData["slug"]; exists && valueOrEmpty(value) != "" {
+		slug = fmt.Sprint(value)
+	}
+	// Pages in different directories or languages get different URLs,
+	// e.g. post/a/index.md and post/b/index.md, or post/hello.md and post/hello.es.md
+	slugKey := filepath.ToSlash(filepath.Join(languageDir(lang), dir, slug))
+	l.slugs[slugKey] = append(l.slugs[slugKey], path)
+
+	return nil
+}
+
+// Check a value against the schema for its field.
+// Returns a description of the problem, or an empty string if it is valid.
+func (l *Linter) checkField(field FieldSchema, value interface{}) string {
+	if value == nil || value == "" {
+		if field.Required {
+			return "is required but empty"
+		}
+		return ""
+	}
+
+	switch field.Type {
+	case "string":
+		if _, isString := value.(string); !isString {
+			return fmt.Sprintf("should be a string, got %T", value)
+		}
+	case "bool":
+		if _, isBool := value.(bool); !isBool {
+			return fmt.Sprintf("should be true or false, got %v", value)
+		}
+	case "number":
+		switch value.(type) {
+		case int, int64, uint64, float64:
+		default:
+			return fmt.Sprintf("should be a number, got %v", value)
+		}
+	case "list":
+		if _, isList := value.([]interface{}); !isList {
+			return fmt.Sprintf("should be a list, got %v", value)
+		}
+	case "map":
+		switch value.(type) {
+		case map[interface{}]interface{}, map[string]interface{}:
+		default:
+			return fmt.Sprintf("should be a map, got %v", value)
+		}
+	case "date":
+		format := field.Format
+		if format == "" {
+			format = defaultDateFormat
+		}
+		date := fmt.Sprint(value)
+		if _, err := time.Parse(format, date); err != nil {
+			return fmt.Sprintf("should be a date formatted as %s, got %q", format, date)
+		}
+	}
+
+	if len(field.Values) > 0 {
+		text := fmt.Sprint(value)
+		for _, allowed := range field.Values {
+			if text == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("should be one of %s, got %q", strings.Join(field.Values, ", "), text)
+	}
+
+	return ""
+}
+
+// Report slugs used by more than one file in the same directory and language
+func (l *Linter) checkDuplicateSlugs() {
+	for slugKey, files := range l.slugs {
+		if len(files) < 2 {
+			continue
+		}
+		for _, file := range files {
+			l.addIssue(file, 1, severityError, fmt.Sprintf("duplicate slug %q is also used by %d other file(s)", slugKey, len(files)-1))
+		}
+	}
+}
+
+// Parse the front matter into a list of keys and values, in the order of the
+// keys for YAML and sorted by key for TOML and JSON
+func (l *Linter) parseFrontMatter(format string, frontMatter string) (yaml.MapSlice, error) {
+	var items yaml.MapSlice
+	if format == frontMatterYAML {
+		err := yaml.Unmarshal([]byte(frontMatter), &items)
+		return items, err
+	}
+
+	metaData, err := parseFrontMatter(format, frontMatter)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range sortedKeys(metaData) {
+		items = append(items, yaml.MapItem{Key: key, Value: metaData[key]})
+	}
+	return items, nil
+}
+
+// Map each top level key in the front matter to its line number
+func (l *Linter) keyLines(format string, frontMatter string, startLine int) map[string]int {
+	keyLines := make(map[string]int)
+	for i, line := range strings.Split(frontMatter, "\n") {
+		separator := ":"
+		switch format {
+		case frontMatterTOML:
+			// Keys after a table header belong to the table
+			if strings.HasPrefix(strings.TrimSpace(line), "[") {
+				return keyLines
+			}
+			separator = "="
+		case frontMatterJSON:
+			line = strings.TrimSpace(line)
+		}
+
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' {
+			continue
+		}
+		key, _, found := strings.Cut(line, separator)
+		key = strings.Trim(strings.TrimSpace(key), `"'`)
+		if _, exists := keyLines[key]; found && !exists {
+			keyLines[key] = startLine + i
+		}
+	}
+	return keyLines
+}
+
+// Returns the language, directory, content type and name of a file, using
+// the same rules as the build. The language is taken out of the directory and name.
+func (l *Linter) contentInfo(relPath string) (string, string, string, string) {
+	fileName := strings.TrimSuffix(filepath.Base(relPath), filepath.Ext(relPath))
+	lang, dir, section, fileName := contentLanguage(filepath.Dir(relPath), fileName)
+	if dir == "." {
+		dir = ""
+	}
+	if section == "" || section == "." {
+		section = "page"
+	}
+	return lang, dir, section, fileName
+}
+
+// Returns the schema for a content type, falling back to the default schema.
+// The default schema also allows the front matter keys weighted for related pages.
+func (l *Linter) schemaFor(contentType string) ContentSchema {
+	if schema, exists := config.Schemas[contentType]; exists {
+		return schema
+	}
+	if schema, exists := config.Schemas["default"]; exists {
+		return schema
+	}
+
+	schema := ContentSchema{Strict: defaultContentSchema.Strict, Fields: make(map[string]FieldSchema)}
+	for key, field := range defaultContentSchema.Fields {
+		schema.Fields[key] = field
+	}
+	for key := range config.Related.Weights {
+		if _, exists := schema.Fields[key]; !exists {
+			schema.Fields[key] = FieldSchema{}
+		}
+	}
+	return schema
+}
+
+// Load the names of the templates, named the same way as the build names them
+func (l *Linter) loadTemplateNames() (map[string]bool, error) {
+	templates := make(map[string]bool)
+	if !filesystem.Exists(l.templateDir) {
+		return templates, nil
+	}
+
+	err := filepath.Walk(l.templateDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".tmpl" {
+			return nil
+		}
+		relPath, err := filepath.Rel(l.templateDir, path)
+		if err != nil {
+			return err
+		}
+		templates[filepath.ToSlash(relPath)] = true
+		return nil
+	})
+
+	return templates, err
+}
+
+// Record a problem found in a content file
+func (l *Linter) addIssue(file string, line int, severity string, message string) {
+	if line < 1 {
+		line = 1
+	}
+	l.issues = append(l.issues, LintIssue{
+		File:     file,
+		Line:     line,
+		Severity: severity,
+		Message:  message,
+	})
+}
+
+// Sort the issues by file and line so the output is stable
+func (l *Linter) sortIssues() {
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		if l.issues[i].File != l.issues[j].File {
+			return l.issues[i].File < l.issues[j].File
+		}
+		if l.issues[i].Line != l.issues[j].Line {
+			return l.issues[i].Line < l.issues[j].Line
+		}
+		return l.issues[i].Message < l.issues[j].Message
+	})
+}
+
+// Print the issues and a summary
+// Returns the number of errors found
+func (l *Linter) printIssues(issues []LintIssue) int {
+	errors := 0
+	for _, issue := range issues {
+		if issue.Severity == severityError {
+			errors++
+			logger.Error("%s:%d: %s", issue.File, issue.Line, issue.Message)
+		} else {
+			logger.Warn("%s:%d: %s", issue.File, issue.Line, issue.Message)
+		}
+	}
+
+	summary := fmt.Sprintf("Found %d errors and %d warnings", errors, len(issues)-errors)
+	if errors > 0 {
+		logger.Error(summary)
+	} else {
+		logger.Success(summary)
+	}
+	return errors
+}
+
+// Returns the keys of a map in sorted order
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Returns an empty string for nil values so they can be compared as text
+func valueOrEmpty(value interface{}) interface{} {
+	if value == nil {
+		return ""
+	}
+	return value
+}
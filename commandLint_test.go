@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLinter_LintContent(t *testing.T) {
+	restoreConfig(t)
+	config.Related.Weights = map[string]int{"tags": 3, "keywords": 1}
+
+	rootDir := t.TempDir()
+	contentDir := filepath.Join(rootDir, "content")
+	templateDir := filepath.Join(rootDir, "template")
+	files := map[string]string{
+		"template/default.tmpl": `{{ .Content }}`,
+		"content/post/good.md":  "---\ntitle: Good\nindex: true\npublish_date: 2024-01-30\ntemplate: default.tmpl\n---\n# Good\n",
+		"content/post/bad.md":   "---\ntitle: \"\"\nnoindex: false\nindex: yes please\npublish_date: 01/30/2024\ntemplate: blog-post.tmpl\nslug: good\n---\n# Bad\n",
+		"content/post/none.md":  "# No front matter\n",
+		// Every key the build reads is valid in the default schema, with the keys weighted for related pages
+		"content/post/full.md": "---\ntitle: Full\ndate: 2024-02-01\ncategories: [go]\nkeywords: [web]\ncanonical: https://example.com/full/\n" +
+			"translationKey: full\nseries: Go\nseries_order: 1\naliases: [/old/full/]\n---\n# Full\n",
+		// Page bundles share the index file name but not their directory
+		"content/post/a/index.md": "---\ntitle: A\n---\n# A\n",
+		"content/post/b/index.md": "---\ntitle: B\n---\n# B\n",
+	}
+	for name, content := range files {
+		filePath := filepath.Join(rootDir, name)
+		os.MkdirAll(filepath.Dir(filePath), 0755)
+		os.WriteFile(filePath, []byte(content), 0644)
+	}
+
+	var linter Linter
+	issues, err := linter.LintContent(contentDir, templateDir)
+	if err != nil {
+		t.Fatalf("Failed to lint content: %s", err)
+	}
+
+	// Each problem is reported with the line of the key
+	want := []string{
+		"bad.md:1: duplicate slug",
+		"bad.md:2: title is required but empty",
+		"bad.md:3: unknown key \"noindex\"",
+		"bad.md:4: index should be true or false",
+		"bad.md:5: publish_date should be a date",
+		"bad.md:6: unknown template \"blog-post.tmpl\"",
+		"good.md:1: duplicate slug",
+		"none.md:1: missing front matter",
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("Issue count mismatch. Got: %d, Want: %d (%v)", len(issues), len(want), issues)
+	}
+	for i, issue := range issues {
+		got := fmt.Sprintf("%s:%d: %s", filepath.Base(issue.File), issue.Line, issue.Message)
+		if !strings.HasPrefix(got, want[i]) {
+			t.Errorf("Issue mismatch. Got: %s, Want prefix: %s", got, want[i])
+		}
+	}
+}
+
+func TestLinter_LintContentLanguages(t *testing.T) {
+	restoreConfig(t)
+	config.DefaultLanguage = "en"
+	config.Languages = map[string]LanguageConfig{"en": {Name: "English"}, "es": {Name: "Español"}}
+	config.Schemas = map[string]ContentSchema{
+		"post": {Strict: true, Fields: map[string]FieldSchema{"title": {Type: "string"}, "slug": {Type: "string"}, "date": {Type: "date"}}},
+	}
+
+	rootDir := t.TempDir()
+	files := map[string]string{
+		// The post schema applies to posts in a language directory
+		"content/es/post/hola.md": "---\ntitle: Hola\ndate: 2024-01-30\n---\n",
+		// Translations can share a slug
+		"content/post/hello.md":    "---\ntitle: Hello\nslug: hi\n---\n",
+		"content/post/hello.es.md": "---\ntitle: Hola\nslug: hi\n---\n",
+		"content/es/post/other.md": "---\ntitle: Otro\nslug: hi\n---\n",
+	}
+	for name, content := range files {
+		filePath := filepath.Join(rootDir, name)
+		os.MkdirAll(filepath.Dir(filePath), 0755)
+		os.WriteFile(filePath, []byte(content), 0644)
+	}
+
+	var linter Linter
+	issues, err := linter.LintContent(filepath.Join(rootDir, "content"), filepath.Join(rootDir, "template"))
+	if err != nil {
+		t.Fatalf("Failed to lint content: %s", err)
+	}
+
+	// Only the two Spanish pages using the same slug are reported
+	want := []string{
+		"content/es/post/other.md:1: duplicate slug \"es/post/hi\"",
+		"content/post/hello.es.md:1: duplicate slug \"es/post/hi\"",
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("Issue count mismatch. Got: %d, Want: %d (%v)", len(issues), len(want), issues)
+	}
+	for i, issue := range issues {
+		relPath, _ := filepath.Rel(rootDir, issue.File)
+		got := fmt.Sprintf("%s:%d: %s", filepath.ToSlash(relPath), issue.Line, issue.Message)
+		if !strings.HasPrefix(got, want[i]) {
+			t.Errorf("Issue mismatch. Got: %s, Want prefix: %s", got, want[i])
+		}
+	}
+}
@@ -0,0 +1,530 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Controls the mv command
+type Mover struct {
+	contentDir string
+	files      map[string]FileInfo // The content files, keyed by their slash separated path
+	moves      map[string]string   // The new path of each moved file, keyed by its old path
+}
+
+// Defining a global varaiable for mv command
+var moveCommand Mover
+
+// MoveOptions holds the options for the mv command
+type MoveOptions struct {
+	Source string // The content to move, relative to the content directory
+	Dest   string // The new location, relative to the content directory
+	DryRun bool   // Report the changes without making them
+}
+
+// A change made (or planned with --dry-run) while moving content
+type MoveChange struct {
+	File    string // The content file that changed, relative to the content directory
+	Message string // A description of the change
+}
+
+// Patterns used to find links to other content in markdown files
+var (
+	markdownLinkPattern = regexp.MustCompile(`\]\(\s*<?([^)\s>]+)>?(?:\s+(?:"[^"]*"|'[^']*'|\([^)]*\)))?\s*\)`)
+	refShortcodePattern = regexp.MustCompile(`\{\{<\s*ref\s+"([^"]+)"\s*>\}\}`)
+)
+
+// **********  Public Mover Methods  **********
+
+// Moves a content file or bundle, adds its old URL to its aliases and
+// rewrites the links in other content that point to it.
+// Returns the changes made, or the changes that would be made with DryRun.
+func (m *Mover) MoveContent(options MoveOptions) ([]MoveChange, error) {
+	m.contentDir = buildCommand.contentDir
+
+	// Load the content with its permalinks, the same way the build does
+	builder := buildCommand
+	dirsMap, err := builder.walkContentDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := builder.collectedErrors(); err != nil {
+		return nil, err
+	}
+	m.files = make(map[string]FileInfo)
+	for _, dirInfo := range dirsMap {
+		for _, file := range dirInfo.Files {
+			m.files[filepath.ToSlash(file.Path)] = file
+		}
+	}
+
+	if err := m.planMoves(options.Source, options.Dest); err != nil {
+		return nil, err
+	}
+
+	var changes []MoveChange
+	contents := make(map[string]string)
+	for _, filePath := range sortedKeys(m.files) {
+		if !isMarkdownFile(filePath) {
+			continue
+		}
+		source, err := filesystem.Read(filepath.Join(m.contentDir, filepath.FromSlash(filePath)))
+		if err != nil {
+			return nil, err
+		}
+		updated, fileChanges := m.rewriteLinks(filePath, source)
+
+		// Record the old URL of moved pages when the move changes it
+		if newPath, moved := m.moves[filePath]; moved {
+			oldURL, newURL, err := m.permalinks(&builder, filePath, newPath)
+			if err != nil {
+				return nil, err
+			}
+			if oldURL != newURL {
+				updated = addAlias(updated, oldURL)
+				fileChanges = append(fileChanges, fmt.Sprintf("add alias %s (now %s)", oldURL, newURL))
+			}
+		}
+
+		if updated != source {
+			contents[filePath] = updated
+		}
+		for _, message := range fileChanges {
+			changes = append(changes, MoveChange{File: filePath, Message: message})
+		}
+	}
+
+	// Report the moves before the other changes
+	var moveChanges []MoveChange
+	for _, oldPath := range sortedKeys(m.moves) {
+		moveChanges = append(moveChanges, MoveChange{File: oldPath, Message: "move to " + m.moves[oldPath]})
+	}
+	changes = append(moveChanges, changes...)
+
+	if options.DryRun {
+		return changes, nil
+	}
+
+	// Write the updated content before moving, so nothing is moved if a write fails
+	for _, filePath := range sortedKeys(contents) {
+		fullPath := filepath.Join(m.contentDir, filepath.FromSlash(filePath))
+		if err := os.WriteFile(fullPath, []byte(contents[filePath]), 0644); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.moveSource(options.Source, options.Dest); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// **********  Private Mover Methods  **********
+
+// Work out the new path of every file being moved
+// The source can be a file, with or without its extension, or a bundle directory
+func (m *Mover) planMoves(source string, dest string) error {
+	source = strings.Trim(filepath.ToSlash(path.Clean(source)), "/")
+	dest = strings.Trim(filepath.ToSlash(path.Clean(dest)), "/")
+	m.moves = make(map[string]string)
+
+	sourcePath := filepath.Join(m.contentDir, filepath.FromSlash(source))
+	info, err := os.Stat(sourcePath)
+	if err == nil && info.IsDir() {
+		// Move every file in the bundle, including resources such as images
+		err := filepath.Walk(sourcePath, func(filePath string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			relPath, err := filepath.Rel(sourcePath, filePath)
+			if err != nil {
+				return err
+			}
+			m.moves[path.Join(source, filepath.ToSlash(relPath))] = path.Join(dest, filepath.ToSlash(relPath))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		if _, exists := m.files[source]; !exists && path.Ext(source) == "" {
+			source += ".md"
+		}
+		if _, exists := m.files[source]; !exists {
+			return fmt.Errorf("no content found at %s", source)
+		}
+		if path.Ext(dest) == "" {
+			dest += path.Ext(source)
+		}
+		m.moves[source] = dest
+	}
+	if len(m.moves) == 0 {
+		return fmt.Errorf("no content found in %s", source)
+	}
+
+	for _, newPath := range m.moves {
+		if filesystem.Exists(filepath.Join(m.contentDir, filepath.FromSlash(newPath))) {
+			return fmt.Errorf("%s already exists", newPath)
+		}
+	}
+
+	return nil
+}
+
+// Rewrite the links in a markdown file that point to moved content, and the
+// relative links in moved files that would break in their new location.
+// Returns the updated content and a description of each change.
+func (m *Mover) rewriteLinks(filePath string, source string) (string, []string) {
+	var changes []string
+	for _, pattern := range []*regexp.Regexp{markdownLinkPattern, refShortcodePattern} {
+		var builder strings.Builder
+		last := 0
+		for _, match := range pattern.FindAllStringSubmatchIndex(source, -1) {
+			destination := source[match[2]:match[3]]
+			updated, changed := m.rewriteLink(filePath, destination, pattern == refShortcodePattern)
+			if !changed {
+				continue
+			}
+			builder.WriteString(source[last:match[2]])
+			builder.WriteString(updated)
+			last = match[3]
+			changes = append(changes, fmt.Sprintf("link %s -> %s", destination, updated))
+		}
+		builder.WriteString(source[last:])
+		source = builder.String()
+	}
+	return source, changes
+}
+
+// Rewrite a single link from a file, keeping the style of the link.
+// Ref shortcodes can leave out the extension, other links must end in .md.
+// Returns the new link and whether it changed.
+func (m *Mover) rewriteLink(filePath string, destination string, isRef bool) (string, bool) {
+	parsed, err := url.Parse(destination)
+	if err != nil || parsed.Scheme != "" || parsed.Host != "" || parsed.Path == "" {
+		return destination, false
+	}
+	targetPath, _ := url.PathUnescape(parsed.Path)
+	hasExtension := path.Ext(targetPath) != ""
+	if !hasExtension && isRef {
+		targetPath += ".md"
+	}
+	if !isMarkdownFile(targetPath) {
+		return destination, false
+	}
+
+	// Find the file the link points to, the same way the build does
+	target, isRelative := m.lookup(filePath, targetPath)
+	if target == "" {
+		return destination, false
+	}
+	newTarget := m.newPath(target)
+	newFile := m.newPath(filePath)
+	if newTarget == target && (newFile == filePath || !isRelative) {
+		return destination, false
+	}
+
+	var updated string
+	switch {
+	case strings.HasPrefix(targetPath, "/"):
+		updated = "/" + newTarget
+	case isRelative:
+		relPath, err := filepath.Rel(filepath.FromSlash(path.Dir(newFile)), filepath.FromSlash(newTarget))
+		if err != nil {
+			return destination, false
+		}
+		updated = filepath.ToSlash(relPath)
+		if strings.HasPrefix(parsed.Path, "./") && !strings.HasPrefix(updated, "../") {
+			updated = "./" + updated
+		}
+	default:
+		updated = newTarget
+	}
+	if !hasExtension {
+		updated = strings.TrimSuffix(updated, ".md")
+	}
+	if parsed.Fragment != "" {
+		updated += "#" + parsed.Fragment
+	}
+
+	return updated, updated != destination
+}
+
+// Look up the content file a link points to.
+// Returns the path of the file and whether the link is relative to the page.
+func (m *Mover) lookup(filePath string, target string) (string, bool) {
+	if strings.HasPrefix(target, "/") {
+		target = path.Clean(strings.TrimPrefix(target, "/"))
+		if _, exists := m.files[target]; exists {
+			return target, false
+		}
+		return "", false
+	}
+	relative := path.Join(path.Dir(filePath), target)
+	if _, exists := m.files[relative]; exists {
+		return relative, true
+	}
+	if _, exists := m.files[path.Clean(target)]; exists {
+		return path.Clean(target), false
+	}
+	return "", false
+}
+
+// Returns the path of a file after the move
+func (m *Mover) newPath(filePath string) string {
+	if newPath, moved := m.moves[filePath]; moved {
+		return newPath
+	}
+	return filePath
+}
+
+// Returns the permalink of a file before and after it is moved
+func (m *Mover) permalinks(builder *Builder, oldPath string, newPath string) (string, string, error) {
+	file := m.files[oldPath]
+
+	dir := path.Dir(newPath)
+	if dir == "." {
+		dir = ""
+	}
+	lang, dir, section, fileName := contentLanguage(dir, strings.TrimSuffix(path.Base(newPath), path.Ext(newPath)))
+	// The page of a bundle is named after its directory, wherever the bundle moves
+	isBundle := builder.bundles[filepath.Dir(filepath.FromSlash(oldPath))]
+	if isBundle {
+		dir, fileName = bundlePage(dir)
+	}
+
+	newURL, err := builder.permalink(lang, dir, section, fileName, file.MetaData)
+	if err == nil && isBundle {
+		newURL = bundlePermalink(newURL, file.MetaData)
+	}
+	return file.OutputPath, newURL, err
+}
+
+// Move the source file or bundle to its new location
+func (m *Mover) moveSource(source string, dest string) error {
+	for oldPath, newPath := range m.moves {
+		oldFile := filepath.Join(m.contentDir, filepath.FromSlash(oldPath))
+		newFile := filepath.Join(m.contentDir, filepath.FromSlash(newPath))
+		if err := os.MkdirAll(filepath.Dir(newFile), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(oldFile, newFile); err != nil {
+			return err
+		}
+	}
+
+	// Remove the bundle directory once its files are moved
+	sourcePath := filepath.Join(m.contentDir, filepath.FromSlash(strings.Trim(source, "/")))
+	if info, err := os.Stat(sourcePath); err == nil && info.IsDir() {
+		return os.RemoveAll(sourcePath)
+	}
+	return nil
+}
+
+// Parse the mv command arguments, allowing flags before or after the paths
+func (m *Mover) parseFlags(args []string) (MoveOptions, error) {
+	options := MoveOptions{}
+	flags := flag.NewFlagSet("mv", flag.ContinueOnError)
+	flags.BoolVar(&options.DryRun, "dry-run", false, "Report the changes without making them")
+
+	var paths []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return options, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		paths = append(paths, args[0])
+		args = args[1:]
+	}
+	if len(paths) != 2 {
+		return options, fmt.Errorf("expected a source and a destination, e.g. repose mv post/old-name post/new-name")
+	}
+	options.Source, options.Dest = paths[0], paths[1]
+
+	return options, nil
+}
+
+// Print the changes
+func (m *Mover) printChanges(changes []MoveChange, dryRun bool) {
+	prefix := ""
+	if dryRun {
+		prefix = "[dry run] "
+	}
+	files := make(map[string][]string)
+	for _, change := range changes {
+		files[change.File] = append(files[change.File], change.Message)
+	}
+	for _, file := range sortedKeys(files) {
+		logger.Info("%s%s", prefix, file)
+		for _, message := range files[file] {
+			fmt.Printf("        %s\n", message)
+		}
+	}
+}
+
+// Add a URL to the aliases in the YAML front matter, adding the front matter
+// or the aliases key when the file doesn't have them
+func addAlias(source string, alias string) string {
+	switch format, frontMatter, _, body := splitFrontMatter(source); format {
+	case frontMatterTOML:
+		return addTomlAlias(source, alias)
+	case frontMatterJSON:
+		return addJSONAlias(source, frontMatter, body, alias)
+	}
+
+	lines := strings.Split(source, "\n")
+	start := 0
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	if start == len(lines) || strings.TrimSpace(lines[start]) != "---" {
+		return "---\naliases:\n  - " + alias + "\n---\n" + source
+	}
+	end := start + 1
+	for end < len(lines) && strings.TrimSpace(lines[end]) != "---" {
+		end++
+	}
+	if end == len(lines) {
+		return source
+	}
+
+	for i := start + 1; i < end; i++ {
+		key, value, found := strings.Cut(lines[i], ":")
+		if !found || strings.TrimSpace(key) != "aliases" || strings.HasPrefix(lines[i], " ") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		// Inline lists: aliases: [/one/, /two/]
+		if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+			items := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"))
+			if items != "" {
+				items += ", "
+			}
+			lines[i] = key + ": [" + items + alias + "]"
+			return strings.Join(lines, "\n")
+		}
+		// A single value: aliases: /one/
+		if value != "" {
+			lines[i] = key + ":\n  - " + value + "\n  - " + alias
+			return strings.Join(lines, "\n")
+		}
+		// Block lists, added after the last item with the same indent
+		last, indent := i, "  "
+		for j := i + 1; j < end; j++ {
+			item := strings.TrimLeft(lines[j], " ")
+			if !strings.HasPrefix(item, "-") {
+				break
+			}
+			last, indent = j, lines[j][:len(lines[j])-len(item)]
+		}
+		lines = append(lines[:last+1], append([]string{indent + "- " + alias}, lines[last+1:]...)...)
+		return strings.Join(lines, "\n")
+	}
+
+	lines = append(lines[:end], append([]string{"aliases:", "  - " + alias}, lines[end:]...)...)
+	return strings.Join(lines, "\n")
+}
+
+// Add a URL to the aliases in TOML front matter, adding the aliases key
+// before the first table when the file doesn't have one
+func addTomlAlias(source string, alias string) string {
+	lines := strings.Split(source, "\n")
+	start := 0
+	for strings.TrimSpace(strings.TrimPrefix(lines[start], "\ufeff")) != "+++" {
+		start++
+	}
+	end := start + 1
+	for end < len(lines) && strings.TrimSpace(lines[end]) != "+++" {
+		end++
+	}
+	quoted := strconv.Quote(alias)
+
+	for i := start + 1; i < end; i++ {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "[") {
+			end = i
+			break
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found || strings.Trim(strings.TrimSpace(key), `"'`) != "aliases" {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		// Inline arrays: aliases = ["/one/", "/two/"]
+		if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+			items := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"))
+			if items != "" {
+				items = strings.TrimSuffix(items, ",") + ", "
+			}
+			lines[i] = lines[i][:strings.Index(lines[i], "=")] + "= [" + items + quoted + "]"
+			return strings.Join(lines, "\n")
+		}
+		// Arrays over several lines, added before the closing bracket
+		for j := i + 1; j < len(lines); j++ {
+			if !strings.HasPrefix(strings.TrimSpace(lines[j]), "]") {
+				continue
+			}
+			last := strings.TrimSpace(lines[j-1])
+			indent := lines[j-1][:len(lines[j-1])-len(strings.TrimLeft(lines[j-1], " \t"))]
+			if j-1 == i {
+				indent = "  "
+			} else if !strings.HasSuffix(last, ",") {
+				lines[j-1] += ","
+			}
+			lines = append(lines[:j], append([]string{indent + quoted + ","}, lines[j:]...)...)
+			return strings.Join(lines, "\n")
+		}
+		return source
+	}
+
+	lines = append(lines[:end], append([]string{"aliases = [" + quoted + "]"}, lines[end:]...)...)
+	return strings.Join(lines, "\n")
+}
+
+// Add a URL to the aliases in JSON front matter.
+// The front matter is written back with its keys sorted.
+func addJSONAlias(source string, frontMatter string, body string, alias string) string {
+	decoder := json.NewDecoder(strings.NewReader(frontMatter))
+	decoder.UseNumber()
+	var metaData map[string]interface{}
+	if err := decoder.Decode(&metaData); err != nil {
+		return source
+	}
+
+	var aliases []interface{}
+	switch value := metaData["aliases"].(type) {
+	case []interface{}:
+		aliases = value
+	case string:
+		aliases = []interface{}{value}
+	}
+	metaData["aliases"] = append(aliases, alias)
+
+	var output bytes.Buffer
+	encoder := json.NewEncoder(&output)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(metaData); err != nil {
+		return source
+	}
+	return source[:strings.Index(source, frontMatter)] + output.String() + body
+}
+
+// Check if a path is a markdown file
+func isMarkdownFile(filePath string) bool {
+	return strings.HasSuffix(filePath, ".md")
+}
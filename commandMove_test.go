@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestAddAlias(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"no aliases", "---\ntitle: Hi\n---\nbody", "---\ntitle: Hi\naliases:\n  - /old/\n---\nbody"},
+		{"inline list", "---\naliases: [/a/]\n---\n", "---\naliases: [/a/, /old/]\n---\n"},
+		{"block list", "---\naliases:\n    - /a/\ntitle: Hi\n---\n", "---\naliases:\n    - /a/\n    - /old/\ntitle: Hi\n---\n"},
+		{"single value", "---\naliases: /a/\n---\n", "---\naliases:\n  - /a/\n  - /old/\n---\n"},
+		{"no front matter", "body", "---\naliases:\n  - /old/\n---\nbody"},
+		{"toml", "+++\ntitle = \"Hi\"\n[params]\nx = 1\n+++\nbody", "+++\ntitle = \"Hi\"\naliases = [\"/old/\"]\n[params]\nx = 1\n+++\nbody"},
+		{"toml inline list", "+++\naliases = [\"/a/\"]\n+++\n", "+++\naliases = [\"/a/\", \"/old/\"]\n+++\n"},
+		{"toml block list", "+++\naliases = [\n  \"/a/\"\n]\n+++\n", "+++\naliases = [\n  \"/a/\",\n  \"/old/\",\n]\n+++\n"},
+		{"json", "{\"title\": \"Hi\", \"weight\": 2}\nbody", "{\n  \"aliases\": [\n    \"/old/\"\n  ],\n  \"title\": \"Hi\",\n  \"weight\": 2\n}\nbody"},
+	}
+	for _, tt := range tests {
+		if got := addAlias(tt.source, "/old/"); got != tt.want {
+			t.Errorf("%s: addAlias mismatch. Got: %q, Want: %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMover_RewriteLinks(t *testing.T) {
+	m := Mover{
+		files: map[string]FileInfo{
+			"about.md":      {Path: "about.md"},
+			"post/old.md":   {Path: "post/old.md"},
+			"post/other.md": {Path: "post/other.md"},
+		},
+		moves: map[string]string{"post/old.md": "blog/new.md"},
+	}
+
+	source := `[a](post/old.md#intro) [b](old.md "Title") [c](/post/old.md) {{< ref "post/old" >}} [d](other.md) [e](https://example.com/post/old.md)`
+	want := `[a](blog/new.md#intro) [b](../blog/new.md "Title") [c](/blog/new.md) {{< ref "blog/new" >}} [d](other.md) [e](https://example.com/post/old.md)`
+	got, changes := m.rewriteLinks("post/other.md", source)
+	if got != want {
+		t.Errorf("rewriteLinks mismatch.\nGot:  %s\nWant: %s", got, want)
+	}
+	if len(changes) != 4 {
+		t.Errorf("Change count mismatch. Got: %d, Want: 4", len(changes))
+	}
+
+	// Relative links in the moved file are updated for its new location
+	got, _ = m.rewriteLinks("post/old.md", "[home](../about.md) [sibling](other.md)")
+	if want := "[home](../about.md) [sibling](../post/other.md)"; got != want {
+		t.Errorf("rewriteLinks in moved file mismatch. Got: %s, Want: %s", got, want)
+	}
+}
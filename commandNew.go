@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ArchetypeData holds the values an archetype can use, e.g. {{ .Title }}
+type ArchetypeData struct {
+	Title    string                 // The title, made from the file name
+	Name     string                 // The file name without the extension, e.g. my-post
+	Date     string                 // Today's date, e.g. 2024-01-30
+	Author   string                 // The author from the config
+	Section  string                 // The content type, e.g. post
+	Sitename string                 // The name of the site
+	URL      string                 // The URL of the site
+	Params   map[string]interface{} // The params from the config
+}
+
+// The directory (relative to the root) that holds the archetypes
+const archetypesDir = "archetypes"
+
+// The archetype used for content types without their own archetype
+const defaultArchetype = "default"
+
+// The file written for a page bundle, when its archetype is a directory
+const bundleIndex = "index.md"
+
+// **********  Private Archetype Methods  **********
+
+// Create new content from the archetype of its content type. Archetypes are
+// looked up as archetypes/<type>/ (a page bundle), archetypes/<type>.md and
+// template/metadata.<type>.yml (the front matter only), then the same for
+// the default archetype, then the built-in front matter.
+// Returns the path of the file to edit.
+func (c *Command) createFromArchetype(config Config, typeDirectory string, contentType string, fileName string, title string) (string, error) {
+	name := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	data := ArchetypeData{
+		Title:    title,
+		Name:     name,
+		Date:     time.Now().Format(defaultDateFormat),
+		Author:   config.Author,
+		Section:  contentType,
+		Sitename: config.Sitename,
+		URL:      config.URL,
+		Params:   config.Params,
+	}
+	path := filepath.Join(config.ContentDirectory, typeDirectory, fileName)
+
+	for _, archetype := range []string{contentType, defaultArchetype} {
+		// A directory is copied as a page bundle, e.g. content/post/my-post/
+		bundle := filepath.Join(buildCommand.rootPath, archetypesDir, archetype)
+		if info, err := os.Stat(bundle); err == nil && info.IsDir() {
+			dir := filepath.Join(config.ContentDirectory, typeDirectory, name)
+			return filepath.Join(dir, bundleIndex), c.createBundle(config, bundle, dir, data)
+		}
+
+		source, isMetadata, found := c.readArchetype(archetype)
+		if !found {
+			continue
+		}
+		content, err := executeArchetype(archetype, source, data)
+		if err != nil {
+			return "", err
+		}
+		if isMetadata {
+			content = "---\n" + strings.TrimSpace(content) + "\n---\n\n# " + title + "\n"
+		}
+		return path, c.writeContent(config, path, content)
+	}
+
+	return path, c.writeContent(config, path, c.defaultContent(contentType, title))
+}
+
+// Read the archetype file for a content type, from archetypes/<type>.md or
+// the front matter in template/metadata.<type>.yml.
+// Returns the source, whether it only holds the front matter and whether it was found.
+func (c *Command) readArchetype(archetype string) (string, bool, bool) {
+	candidates := []struct {
+		path       string
+		isMetadata bool
+	}{
+		{filepath.Join(buildCommand.rootPath, archetypesDir, archetype+".md"), false},
+		{filepath.Join(buildCommand.rootPath, "template", "metadata."+archetype+".yml"), true},
+	}
+	for _, candidate := range candidates {
+		if !filesystem.Exists(candidate.path) {
+			continue
+		}
+		source, err := filesystem.Read(candidate.path)
+		if err == nil {
+			return source, candidate.isMetadata, true
+		}
+	}
+	return "", false, false
+}
+
+// Create a page bundle from an archetype directory. Markdown and HTML files
+// are executed as archetypes, any other file (e.g. an image) is copied as is.
+// The bundle is removed again when a file can't be created.
+func (c *Command) createBundle(config Config, archetype string, dir string, data ArchetypeData) error {
+	if filesystem.Exists(dir) {
+		return fmt.Errorf("%s already exists", dir)
+	}
+
+	err := filepath.Walk(archetype, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(archetype, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, relPath)
+
+		extension := strings.ToLower(filepath.Ext(path))
+		if extension != ".md" && extension != ".html" {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			return os.WriteFile(target, content, 0644)
+		}
+
+		source, err := filesystem.Read(path)
+		if err != nil {
+			return err
+		}
+		content, err := executeArchetype(relPath, source, data)
+		if err != nil {
+			return err
+		}
+		return c.writeContent(config, target, content)
+	})
+	if err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+	return nil
+}
+
+// Write a content file, with its front matter in the format set in the config
+func (c *Command) writeContent(config Config, path string, content string) error {
+	if filepath.Ext(path) == ".md" {
+		converted, err := convertFrontMatter(content, config.FrontMatter)
+		if err != nil {
+			return err
+		}
+		content = converted
+	}
+	if err := filesystem.Create(path, content); err != nil {
+		return fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	return nil
+}
+
+// Execute an archetype as a Go template with the values for the new content
+func executeArchetype(name string, source string, data ArchetypeData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("error parsing archetype %s: %w", name, err)
+	}
+	var output bytes.Buffer
+	if err := tmpl.Execute(&output, data); err != nil {
+		return "", fmt.Errorf("error executing archetype %s: %w", name, err)
+	}
+	return output.String(), nil
+}
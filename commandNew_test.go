@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommand_CreateFromArchetype(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"archetypes/post.md":              "---\ntitle: {{ .Title }}\nsection: {{ .Section }}\ndate: {{ .Date }}\ngithub: {{ .Params.github }}\n---\n",
+		"archetypes/default.md":           "---\ntitle: {{ .Title }}\nauthor: {{ .Author }}\n---\n",
+		"archetypes/gallery/index.md":     "---\ntitle: {{ .Title }}\n---\n![]({{ .Name }}.jpg)\n",
+		"archetypes/gallery/photo.jpg":    "binary {{ .Title }}",
+		"template/metadata.note.yml":      "title: {{ .Title }}\ntags: [note]",
+		"template/metadata.unused.yml":    "title: unused",
+		"content/post/existing-post.md":   "---\ntitle: Existing\n---\n",
+		"archetypes/unused-type/index.md": "unused",
+		"archetypes/broken/a.jpg":         "copied before the error",
+		"archetypes/broken/index.md":      "---\ntitle: {{ .Title\n---\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	rootPath := buildCommand.rootPath
+	buildCommand.rootPath = root
+	defer func() { buildCommand.rootPath = rootPath }()
+
+	siteConfig := Config{
+		ContentDirectory: filepath.Join(root, "content"),
+		Author:           "Ann",
+		Params:           map[string]interface{}{"github": "ann"},
+	}
+	today := time.Now().Format(defaultDateFormat)
+
+	tests := []struct {
+		name          string
+		typeDirectory string
+		fileName      string
+		title         string
+		wantPath      string
+		want          string
+	}{
+		{"type archetype", "post", "my-post.md", "My Post", "post/my-post.md",
+			"---\ntitle: My Post\nsection: post\ndate: " + today + "\ngithub: ann\n---\n"},
+		{"subdirectory", "post/2024", "later.md", "Later", "post/2024/later.md",
+			"---\ntitle: Later\nsection: post\ndate: " + today + "\ngithub: ann\n---\n"},
+		{"metadata file", "note", "idea.md", "Idea", "note/idea.md",
+			"---\ntitle: Idea\ntags: [note]\n---\n\n# Idea\n"},
+		{"default archetype", "page", "about.md", "About", "page/about.md",
+			"---\ntitle: About\nauthor: Ann\n---\n"},
+		{"page bundle", "gallery", "trip.md", "Trip", "gallery/trip/index.md",
+			"---\ntitle: Trip\n---\n![](trip.jpg)\n"},
+	}
+	for _, tt := range tests {
+		path, err := command.createFromArchetype(siteConfig, tt.typeDirectory, strings.Split(tt.typeDirectory, "/")[0], tt.fileName, tt.title)
+		if err != nil {
+			t.Errorf("%s: createFromArchetype returned an error: %v", tt.name, err)
+			continue
+		}
+		if want := filepath.Join(root, "content", filepath.FromSlash(tt.wantPath)); path != want {
+			t.Errorf("%s: path mismatch. Got: %s, Want: %s", tt.name, path, want)
+		}
+		content, _ := os.ReadFile(path)
+		if string(content) != tt.want {
+			t.Errorf("%s: content mismatch. Got: %q, Want: %q", tt.name, content, tt.want)
+		}
+	}
+
+	// Files other than markdown and HTML are copied into bundles as they are
+	photo, err := os.ReadFile(filepath.Join(root, "content", "gallery", "trip", "photo.jpg"))
+	if err != nil || string(photo) != "binary {{ .Title }}" {
+		t.Errorf("Expected the photo copied as is. Got: %q, %v", photo, err)
+	}
+
+	// Existing content isn't overwritten
+	if _, err := command.createFromArchetype(siteConfig, "post", "post", "existing-post.md", "Existing Post"); err == nil {
+		t.Errorf("Expected an error for existing content")
+	}
+	if _, err := command.createFromArchetype(siteConfig, "gallery", "gallery", "trip.md", "Trip"); err == nil {
+		t.Errorf("Expected an error for an existing bundle")
+	}
+
+	// A bundle that fails part way isn't left behind
+	if _, err := command.createFromArchetype(siteConfig, "broken", "broken", "trip.md", "Trip"); err == nil {
+		t.Errorf("Expected an error for a broken archetype")
+	}
+	if _, err := os.Stat(filepath.Join(root, "content", "broken", "trip")); !os.IsNotExist(err) {
+		t.Errorf("Expected the partly written bundle to be removed. Got: %v", err)
+	}
+}
+
+func TestCommand_CreateFromArchetype_BuiltIn(t *testing.T) {
+	restoreConfig(t)
+	root := t.TempDir()
+	rootPath := buildCommand.rootPath
+	buildCommand.rootPath = root
+	config.Author = "Ann"
+	defer func() { buildCommand.rootPath = rootPath }()
+
+	siteConfig := Config{ContentDirectory: filepath.Join(root, "content"), FrontMatter: frontMatterTOML}
+	path, err := command.createFromArchetype(siteConfig, "post", "post", "hello.md", "Hello")
+	if err != nil {
+		t.Fatalf("createFromArchetype returned an error: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(content), "+++\ntitle = \"Hello\"\n") || !strings.Contains(string(content), "author = \"Ann\"") {
+		t.Errorf("Expected the built-in front matter as TOML. Got: %s", content)
+	}
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
 )
 
 // Config struct to hold the configuration values
@@ -29,6 +33,67 @@ type Config struct {
 	// Theme is the theme to use for the site
 	// Defaults to picocss, but can be bootstrap or tailwind
 	Theme string `yaml:"theme"`
+	// Params holds any other values for the site, used in templates as
+	// .Site.Params and in archetypes as .Params
+	Params map[string]interface{} `yaml:"params"`
+	// Markdown controls the render hooks applied when converting markdown
+	Markdown MarkdownConfig `yaml:"markdown"`
+	// RefLinks controls what happens when a link points to content that doesn't exist
+	// Can be warn, error or ignore - defaults to warn
+	RefLinks string `yaml:"refLinks"`
+	// FrontMatter is the front matter format of new content: yaml, toml or json
+	// Content files can use any of them - defaults to yaml
+	FrontMatter string `yaml:"frontMatter"`
+	// UglyURLs writes pages to /about.html instead of /about/index.html
+	// Defaults to true for sites without the setting, new sites are created with false
+	UglyURLs bool `yaml:"uglyURLs"`
+	// Permalinks sets the URL pattern for the pages of each content type,
+	// e.g. post: /:year/:month/:slug/
+	// Patterns can use :year, :month, :day, :section, :slug, :title and :filename
+	// Pages need publish_date or date in their front matter to use :year, :month or :day
+	Permalinks map[string]string `yaml:"permalinks"`
+	// Menus holds the navigation menus, keyed by name (e.g. main)
+	// Pages can add themselves to a menu with `menu: main` in their front matter
+	Menus map[string]Menu `yaml:"menus"`
+	// Generators create a page for each record in a data file
+	Generators []GeneratorConfig `yaml:"generators"`
+	// Search controls the JSON search index used by the search page
+	Search SearchConfig `yaml:"search"`
+	// Archives controls the date archive pages, e.g. /post/2024/01/
+	Archives ArchiveConfig `yaml:"archives"`
+	// Series controls the index pages of the series, e.g. /series/go-tutorial/
+	Series SeriesConfig `yaml:"series"`
+	// Authors controls the page and feed written for each author, e.g. /authors/ron/
+	Authors AuthorsConfig `yaml:"authors"`
+	// Related controls the related pages listed on each page as .Related
+	Related RelatedConfig `yaml:"related"`
+	// SEO holds the fallbacks for the description, image and social tags of each page
+	SEO SEOConfig `yaml:"seo"`
+	// Feeds controls the RSS feeds written for the site and each section
+	Feeds FeedConfig `yaml:"feeds"`
+	// DefaultLanguage is the language of the content without a language,
+	// written at the root of the site - defaults to en
+	DefaultLanguage string `yaml:"defaultLanguage"`
+	// Languages lists the languages of a multilingual site, keyed by code (e.g. es)
+	// Content is in a language with a suffix (about.es.md) or a directory (es/about.md)
+	Languages map[string]LanguageConfig `yaml:"languages"`
+	// Schemas describes the front matter for each content type, used by `repose lint`
+	// Use "default" for content types without their own schema
+	Schemas map[string]ContentSchema `yaml:"schemas"`
+}
+
+// MarkdownConfig holds the options for rendering markdown content
+// Each hook can be replaced by a template in template/_hooks/
+type MarkdownConfig struct {
+	// ExternalLinks adds rel="noopener" and target="_blank" to links to other sites
+	// Defaults to true
+	ExternalLinks bool `yaml:"externalLinks"`
+	// ImageFigures wraps images that sit on their own line in a <figure>
+	// Defaults to true
+	ImageFigures bool `yaml:"imageFigures"`
+	// HeadingAnchors adds an anchor link to each heading
+	// Defaults to true
+	HeadingAnchors bool `yaml:"headingAnchors"`
 }
 
 // Create a global config variable so it can be accessed from anywhere
@@ -40,7 +105,7 @@ const ConfigFile = "config.yml"
 // **********  Public Config Methods  **********
 
 // Loads the site configuration from the config file
-// We use this instead of loading the YAML modules to keep the size down
+// Values missing from the file keep their defaults
 func (c *Config) Load() (Config, error) {
 	// Read the entire config file content
 	configPath := filepath.Join(buildCommand.rootPath, ConfigFile)
@@ -49,35 +114,33 @@ func (c *Config) Load() (Config, error) {
 		return Config{}, err
 	}
 
-	// Use readYAML to parse the content
-	yamlMap, err := filesystem.ParseYml(string(data))
+	// Check for empty content
+	if len(data) == 0 {
+		return Config{}, errors.New("parsing failed: empty yaml content")
+	}
+
+	config, err = c.parse(data)
 	if err != nil {
 		return Config{}, err
 	}
 
-	// Populate the Config struct with data from the map
-	config.Sitename = yamlMap["sitename"]
-	config.Author = yamlMap["author"]
-	config.Editor = yamlMap["editor"]
-	config.ContentDirectory = yamlMap["contentDirectory"]
-	config.OutputDirectory = yamlMap["outputDirectory"]
-	config.URL = yamlMap["url"]
-	config.Theme = yamlMap["theme"]
-	config.PreviewURL = yamlMap["previewUrl"]
-
 	return config, nil
 }
 
-// Create initializes the site configuration file
-func (c *Config) Create(installDir string) error {
+// Create writes the configuration file for a new site
+func (c *Config) Create(installDir string, options InitOptions) error {
 	logger.Info("Initializing config file")
-	sitename := command.promptForInput("Enter the site name", "Repose site")
-	author := command.promptForInput("Enter the author's name", "Creator")
-	editor := command.promptForInput("Enter the editor ('none' for no editing)", "nano")
-	url := command.promptForInput("Enter the site URL", "mysite.com")
-	theme := command.promptForInput("Enter the CSS theme to use (pico, bootstrap, tailwind, none)", "pico")
+	sitename, author, editor, url, theme := options.Sitename, options.Author, options.Editor, options.URL, options.Theme
 
-	configContent := fmt.Sprintf(configTemplate, sitename, author, editor, "content", "web", url, "http://localhost:8080", theme)
+	values := []interface{}{}
+	for _, value := range []string{sitename, author, editor, "content", "web", url, "http://localhost:8080", theme} {
+		quoted, err := yamlString(value)
+		if err != nil {
+			return err
+		}
+		values = append(values, quoted)
+	}
+	configContent := fmt.Sprintf(configTemplate, values...)
 
 	// Create the filepath
 	configPath := filepath.Join(installDir, ConfigFile)
@@ -93,6 +156,64 @@ func (c *Config) Create(installDir string) error {
 	return nil
 }
 
+// **********  Private Config Methods  **********
+
+// Parse the config file, values missing from the file keep their defaults
+func (c *Config) parse(data []byte) (Config, error) {
+	loaded := c.defaults()
+	if err := yaml.Unmarshal(data, &loaded); err != nil {
+		return Config{}, fmt.Errorf("error parsing %s: %w", ConfigFile, err)
+	}
+	return loaded, nil
+}
+
+// Returns a string as a YAML scalar, quoted when it needs to be, so values
+// like "A: B" or "C# notes" are read back as they were given
+func yamlString(value string) (string, error) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// Returns a config with the default values set
+func (c *Config) defaults() Config {
+	return Config{
+		ContentDirectory: "content",
+		OutputDirectory:  "web",
+		PreviewURL:       "http://localhost:8080",
+		RefLinks:         refLinksWarn,
+		UglyURLs:         true,
+		FrontMatter:      frontMatterYAML,
+		Markdown: MarkdownConfig{
+			ExternalLinks:  true,
+			ImageFigures:   true,
+			HeadingAnchors: true,
+		},
+		Search: SearchConfig{
+			Enabled:       true,
+			Fields:        defaultSearchFields,
+			SummaryLength: 160,
+		},
+		Feeds: FeedConfig{
+			Enabled: true,
+			Limit:   20,
+		},
+		Series: SeriesConfig{
+			Path: "/series/",
+		},
+		Authors: AuthorsConfig{
+			Path: "/authors/",
+		},
+		Related: RelatedConfig{
+			Limit:   5,
+			Weights: map[string]int{"tags": 3, "categories": 2, relatedTitleKey: 1},
+		},
+		DefaultLanguage: "en",
+	}
+}
+
 // The template for the config file
 const configTemplate = `sitename: %s
 author: %s
@@ -102,4 +223,52 @@ outputDirectory: %s
 url: %s
 previewUrl: %s
 theme: %s
+refLinks: warn
+uglyURLs: false
+frontMatter: yaml
+permalinks:
+  # post: /:year/:month/:slug/
+params:
+  # github: https://github.com/me
+menus:
+  main:
+    - name: Home
+      url: /
+      weight: 1
+search:
+  enabled: true
+  fields: [title, url, summary, tags, section, content]
+  tokenize: false
+archives:
+  sections: [post]
+  path: /archive/
+series:
+  path: /series/
+authors:
+  path: /authors/
+related:
+  limit: 5
+  weights:
+    tags: 3
+    categories: 2
+    title: 1
+seo:
+  description: 
+  image: 
+  twitter: 
+feeds:
+  enabled: true
+  limit: 20
+defaultLanguage: en
+# languages:
+#   en:
+#     name: English
+#     weight: 1
+#   es:
+#     name: Español
+#     weight: 2
+markdown:
+  externalLinks: true
+  imageFigures: true
+  headingAnchors: true
 `
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Restore the global config once the test is done, so tests that change it
+// don't depend on the order they run in
+func restoreConfig(t *testing.T) {
+	t.Helper()
+	saved := config
+	t.Cleanup(func() { config = saved })
+}
+
+func TestConfig_CreateQuotesValues(t *testing.T) {
+	root := t.TempDir()
+	options := InitOptions{Sitename: "A: B", Author: "Ron #1 fan", Editor: "code --wait", URL: "example.com/#top", Theme: "none"}
+	if err := config.Create(root, options); err != nil {
+		t.Fatalf("Create returned an error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, ConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := config.parse(data)
+	if err != nil {
+		t.Fatalf("parse returned an error: %v\n%s", err, data)
+	}
+	if loaded.Sitename != options.Sitename || loaded.Author != options.Author || loaded.Editor != options.Editor || loaded.URL != options.URL {
+		t.Errorf("Values don't round-trip. Got: %q, %q, %q, %q", loaded.Sitename, loaded.Author, loaded.Editor, loaded.URL)
+	}
+}
+
+func TestConfig_ParseDefaults(t *testing.T) {
+	loaded, err := config.parse([]byte("sitename: Old site\n"))
+	if err != nil {
+		t.Fatalf("parse returned an error: %v", err)
+	}
+	// Features added later stay off for configs that don't mention them
+	if len(loaded.Archives.Sections) != 0 || loaded.Archives.Path != "" {
+		t.Errorf("Archives should be off by default. Got: %v", loaded.Archives)
+	}
+	if !loaded.UglyURLs {
+		t.Error("Old configs should keep ugly URLs")
+	}
+}
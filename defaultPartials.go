@@ -0,0 +1,56 @@
+package main
+
+// The built in partials, keyed by name.
+// A site can override any of these with template/partials/<name>.tmpl
+var DefaultPartials = map[string]string{
+	"seo": SEOPartial,
+}
+
+// Usage: {{ partial "seo" . }} in the head of a full page template
+// Adds the description, canonical URL, OpenGraph and Twitter tags and the
+// JSON-LD structured data for the page
+const SEOPartial = `{{- with .SEO }}
+{{- with .Description }}
+<meta name="description" content="{{ . }}">
+{{- end }}
+{{- with .Canonical }}
+<link rel="canonical" href="{{ . }}">
+{{- end }}
+{{- if .NoIndex }}
+<meta name="robots" content="noindex">
+{{- end }}
+{{- range .Authors }}
+<meta name="author" content="{{ . }}">
+{{- end }}
+<meta property="og:site_name" content="{{ .SiteName }}">
+<meta property="og:title" content="{{ .Title }}">
+{{- with .Description }}
+<meta property="og:description" content="{{ . }}">
+{{- end }}
+<meta property="og:type" content="{{ .Type }}">
+{{- with .Canonical }}
+<meta property="og:url" content="{{ . }}">
+{{- end }}
+{{- with .Locale }}
+<meta property="og:locale" content="{{ . }}">
+{{- end }}
+{{- with .Image }}
+<meta property="og:image" content="{{ . }}">
+{{- end }}
+{{- with .Published }}
+<meta property="article:published_time" content="{{ . }}">
+{{- end }}
+<meta name="twitter:card" content="{{ .TwitterCard }}">
+{{- with .Twitter }}
+<meta name="twitter:site" content="{{ . }}">
+{{- end }}
+<meta name="twitter:title" content="{{ .Title }}">
+{{- with .Description }}
+<meta name="twitter:description" content="{{ . }}">
+{{- end }}
+{{- with .Image }}
+<meta name="twitter:image" content="{{ . }}">
+{{- end }}
+<script type="application/ld+json">{{ .JSONLD }}</script>
+{{- end }}
+`
@@ -0,0 +1,159 @@
+package main
+
+// The search page template, shared by every theme.
+// It extends the full page layout and loads the search script, which reads
+// the search index for the page's language (e.g. /es/search.json) - no
+// server needed.
+const SearchTemplate = `{{/* extends "fullpage.tmpl" */}}
+<!-- search.tmpl -->
+{{ define "main" }}
+<article class="search">
+    {{ .Content }}
+    <form role="search" action="" onsubmit="return false">
+        <input type="search" id="search-input" name="q" placeholder="{{ T "Search" }}" aria-label="{{ T "Search" }}" autocomplete="off" data-index="{{ .Site.Prefix }}/search.json" autofocus>
+    </form>
+    <p id="search-status" aria-live="polite"></p>
+    <ul id="search-results"></ul>
+</article>
+<script src="/assets/js/search.js" defer></script>
+{{ end }}
+`
+
+// The content for the search page
+const SearchMD = `
+---
+title: Search
+description: Search the site
+index: false
+template: search.tmpl
+menu:
+  main:
+    weight: 90
+---
+
+# Search
+`
+
+// The search script for the search page.
+// The stemmer and stop words must match the ones in buildSearch.go, so a
+// query finds the terms in a tokenized index.
+const SearchJS = `// search.js - searches the site with the search index built by repose
+(function () {
+    var input = document.getElementById("search-input");
+    var results = document.getElementById("search-results");
+    var status = document.getElementById("search-status");
+    if (!input || !results) {
+        return;
+    }
+
+    var stopWords = new Set(["a", "an", "and", "are", "as", "at", "be", "but", "by", "for",
+        "if", "in", "into", "is", "it", "no", "not", "of", "on", "or", "such", "that", "the",
+        "their", "then", "there", "these", "they", "this", "to", "was", "will", "with"]);
+    var suffixes = [["sses", "ss"], ["ies", "y"], ["ied", "y"], ["ingly", ""], ["edly", ""],
+        ["ing", ""], ["ed", ""], ["ly", ""], ["ss", "ss"], ["us", "us"], ["is", "is"], ["s", ""]];
+    var pages = [];
+
+    // A light English stemmer, the same as the one used to build the index
+    function stem(word) {
+        if (word.length <= 3 || !/^[a-z0-9]+$/.test(word)) {
+            return word;
+        }
+        for (var i = 0; i < suffixes.length; i++) {
+            var suffix = suffixes[i][0], replace = suffixes[i][1];
+            if (!word.endsWith(suffix)) {
+                continue;
+            }
+            var stemmed = word.slice(0, word.length - suffix.length) + replace;
+            if (stemmed.length < 3) {
+                break;
+            }
+            word = stemmed;
+            var last = word[word.length - 1];
+            if (replace === "" && suffix !== "s" && word.length > 3 &&
+                last === word[word.length - 2] && "aeiouls".indexOf(last) < 0) {
+                word = word.slice(0, -1);
+            }
+            break;
+        }
+        if (word.length > 3 && word.endsWith("e")) {
+            word = word.slice(0, -1);
+        }
+        return word;
+    }
+
+    function terms(text) {
+        return text.toLowerCase().split(/[^\p{L}\p{N}]+/u).filter(function (word) {
+            return word.length >= 2 && !stopWords.has(word);
+        }).map(stem);
+    }
+
+    // Score a page for the query terms, favouring matches in the title and tags
+    function score(page, query) {
+        var total = 0;
+        var pageTerms = page.terms ? page.terms.split(" ") : null;
+        var fields = [
+            [terms(page.title || ""), 5],
+            [terms((page.tags || []).join(" ")), 3],
+            [terms(page.summary || ""), 2],
+            [pageTerms || terms(page.content || ""), 1]
+        ];
+        for (var i = 0; i < query.length; i++) {
+            var matched = 0;
+            for (var j = 0; j < fields.length; j++) {
+                if (fields[j][0].some(function (term) { return term.startsWith(query[i]); })) {
+                    matched += fields[j][1];
+                }
+            }
+            if (matched === 0) {
+                return 0;
+            }
+            total += matched;
+        }
+        return total;
+    }
+
+    function search() {
+        var query = terms(input.value);
+        results.textContent = "";
+        if (query.length === 0) {
+            status.textContent = "";
+            return;
+        }
+
+        var matches = pages.map(function (page) {
+            return { page: page, score: score(page, query) };
+        }).filter(function (match) {
+            return match.score > 0;
+        }).sort(function (a, b) {
+            return b.score - a.score;
+        });
+
+        status.textContent = matches.length + (matches.length === 1 ? " result" : " results");
+        matches.forEach(function (match) {
+            var item = document.createElement("li");
+            var link = document.createElement("a");
+            link.href = match.page.url;
+            link.textContent = match.page.title || match.page.url;
+            item.appendChild(link);
+            if (match.page.summary) {
+                var summary = document.createElement("p");
+                summary.textContent = match.page.summary;
+                item.appendChild(summary);
+            }
+            results.appendChild(item);
+        });
+    }
+
+    input.value = new URLSearchParams(window.location.search).get("q") || "";
+    input.addEventListener("input", search);
+
+    fetch(input.dataset.index || "/search.json").then(function (response) {
+        return response.json();
+    }).then(function (index) {
+        pages = index;
+        search();
+    }).catch(function () {
+        status.textContent = "The search index could not be loaded.";
+    });
+})();
+`
@@ -0,0 +1,49 @@
+package main
+
+// The built in shortcodes, keyed by name.
+// A site can override any of these with template/shortcodes/<name>.tmpl
+var DefaultShortcodes = map[string]string{
+	"figure":  FigureShortcode,
+	"youtube": YoutubeShortcode,
+	"gist":    GistShortcode,
+	"callout": CalloutShortcode,
+	"details": DetailsShortcode,
+	"ref":     RefShortcode,
+}
+
+// Usage: {{< figure src="/img/photo.jpg" alt="A photo" caption="My caption" >}}
+const FigureShortcode = `<figure>
+    <img src="{{ or (.Get "src") (.Get 0) }}" alt="{{ or (.Get "alt") (.Get "caption") }}">
+    {{- with .Get "caption" }}
+    <figcaption>{{ . }}</figcaption>
+    {{- end }}
+</figure>`
+
+// Usage: {{< youtube dQw4w9WgXcQ >}} or {{< youtube id="dQw4w9WgXcQ" title="A video" >}}
+const YoutubeShortcode = `<div class="video">
+    <iframe src="https://www.youtube-nocookie.com/embed/{{ or (.Get "id") (.Get 0) }}" title="{{ or (.Get "title") "YouTube video" }}" allow="accelerometer; encrypted-media; gyroscope; picture-in-picture" allowfullscreen loading="lazy"></iframe>
+</div>`
+
+// Usage: {{< gist username gistid >}}
+// Renders a static link instead of the embed script to keep pages script free
+const GistShortcode = `<p class="gist">
+    <a href="https://gist.github.com/{{ or (.Get "user") (.Get 0) }}/{{ or (.Get "id") (.Get 1) }}">View the gist on GitHub</a>
+</p>`
+
+// Usage: {{< callout type="warning" title="Heads up" >}}Some **markdown**{{< /callout >}}
+const CalloutShortcode = `<aside class="callout callout-{{ or (.Get "type") (.Get 0) "note" }}">
+    {{- with .Get "title" }}
+    <strong>{{ . }}</strong>
+    {{- end }}
+    {{ .Inner }}
+</aside>`
+
+// Usage: {{< details "Click to expand" >}}Hidden **markdown**{{< /details >}}
+const DetailsShortcode = `<details>
+    <summary>{{ or (.Get "summary") (.Get 0) "Details" }}</summary>
+    {{ .Inner }}
+</details>`
+
+// Usage: [see part 2]({{< ref "tutorial-2.md" >}})
+// Returns the output path of the content file, relative to the page or content directory
+const RefShortcode = `{{ ref .Page (.Get 0) }}`
@@ -2,8 +2,26 @@ package main
 
 const DefaultTemplate_none = `<!-- default.tmpl -->
 <article>
+    {{ with .Authors }}<p class="byline">{{ T "By" }} {{ range $i, $author := . }}{{ if $i }}, {{ end }}{{ if .URL }}<a href="{{ .URL }}" rel="author">{{ .Name }}</a>{{ else }}{{ .Name }}{{ end }}{{ end }}</p>{{ end }}
     <div>{{ .Content }}</div>
 </article>
+{{ with .Series }}
+<nav class="series">
+    <p>{{ T "Part %d of %d" .Position .Total }} - {{ if .URL }}<a href="{{ .URL }}">{{ .Name }}</a>{{ else }}{{ .Name }}{{ end }}</p>
+    {{ with .Prev }}<a href="{{ .OutputPath }}" rel="prev">&larr; {{ or .MetaData.title .Name }}</a>{{ end }}
+    {{ with .Next }}<a href="{{ .OutputPath }}" rel="next">{{ or .MetaData.title .Name }} &rarr;</a>{{ end }}
+</nav>
+{{ end }}
+{{ with .Related }}
+<aside class="related">
+    <h2>{{ T "Related" }}</h2>
+    <ul>
+        {{ range . }}
+        <li><a href="{{ .OutputPath }}">{{ or .MetaData.title .Name }}</a></li>
+        {{ end }}
+    </ul>
+</aside>
+{{ end }}
 `
 
 const ListTemplate_none = `<!-- list.tmpl -->
@@ -18,18 +36,25 @@ const ListTemplate_none = `<!-- list.tmpl -->
 
 const PageTemplate_none = `<!-- fullpage.tmpl -->
 <!DOCTYPE html>
-<html lang="en">
+<html lang="{{ .Page.Language }}">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>{{ .Title }}</title>
+    {{ block "head" . }}<title>{{ .Title }}</title>{{ end }}
+    {{ partial "seo" . }}
+    {{ with .Page.Translations }}
+    <link rel="alternate" hreflang="{{ $.Page.Language }}" href="{{ absURL $.Page.OutputPath }}">
+    {{ range . }}
+    <link rel="alternate" hreflang="{{ .Language }}" href="{{ absURL .OutputPath }}">
+    {{ end }}
+    {{ end }}
     <link rel="stylesheet" href="/asset/css/styles.css">
 </head>
 <body>
     {{ template "header.tmpl" . }}
     {{ template "navigation.tmpl" . }}
     <div class="main container">
-        {{ .Content }}
+        {{ block "main" . }}{{ .Content }}{{ end }}
     </div>
     {{ template "footer.tmpl" . }}
 </body>
@@ -46,9 +71,21 @@ const HeaderTemplate_none = `<!-- header.tmpl -->
 const NavigationTemplate_none = `<!-- navigation.tmpl -->
 <nav>
     <ul>
-        <li><a href="/">Home</a></li>
-        <li><a href="/about">About Us</a></li>
-        <li><a href="/contact">Contact</a></li>
+        {{ range .Site.Menus.main }}
+        <li>
+            <a href="{{ .URL }}"{{ if .IsActive $.Page }} aria-current="page"{{ end }}>{{ .Name }}</a>
+            {{ if .Children }}
+            <ul>
+                {{ range .Children }}
+                <li><a href="{{ .URL }}"{{ if .IsActive $.Page }} aria-current="page"{{ end }}>{{ .Name }}</a></li>
+                {{ end }}
+            </ul>
+            {{ end }}
+        </li>
+        {{ end }}
+        {{ range .Page.Translations }}
+        <li><a href="{{ .OutputPath }}" hreflang="{{ .Language }}" lang="{{ .Language }}">{{ .LanguageName }}</a></li>
+        {{ end }}
     </ul>
 </nav>
 `
@@ -62,3 +99,51 @@ const FooterTemplate_none = `<!-- footer.tmpl -->
 const css_none = `/* styles.css */
     
 `
+
+// The archive template, shared by every theme.
+// Lists the pages of a year, a month or the whole site by year and month.
+const ArchiveTemplate = `<!-- archive.tmpl -->
+<article class="archive">
+    <h1>{{ .Title }}</h1>
+    {{ range .Files.GroupByDate "2006" }}
+    <section>
+        <h2>{{ .Key }}</h2>
+        {{ range .Pages.GroupByDate "January" }}
+        <h3>{{ .Key }} ({{ len .Pages }})</h3>
+        <ul>
+            {{ range .Pages }}
+            <li><time datetime="{{ .Date.Format "2006-01-02" }}">{{ .Date.Format "Jan 2" }}</time> <a href="{{ .OutputPath }}">{{ or .MetaData.title .Name }}</a></li>
+            {{ end }}
+        </ul>
+        {{ end }}
+    </section>
+    {{ end }}
+</article>
+`
+
+// The author template, shared by every theme.
+// Shows the profile of an author and lists their pages, newest first.
+const AuthorTemplate = `<!-- author.tmpl -->
+<article class="author">
+    {{ with .Author }}
+    <header>
+        {{ with .Avatar }}<img src="{{ . }}" alt="" width="96" height="96">{{ end }}
+        <h1>{{ .Name }}</h1>
+    </header>
+    {{ .Bio }}
+    {{ with .Social }}
+    <ul class="social">
+        {{ range $network, $link := . }}
+        <li><a href="{{ $link }}" rel="me">{{ $network }}</a></li>
+        {{ end }}
+    </ul>
+    {{ end }}
+    {{ end }}
+    <h2>{{ T "Posts" }}</h2>
+    <ul>
+        {{ range .Files }}
+        <li><time datetime="{{ .Date.Format "2006-01-02" }}">{{ .Date.Format "Jan 2, 2006" }}</time> <a href="{{ .OutputPath }}">{{ or .MetaData.title .Name }}</a></li>
+        {{ end }}
+    </ul>
+</article>
+`
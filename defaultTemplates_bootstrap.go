@@ -2,8 +2,26 @@ package main
 
 const DefaultTemplate_bootstrap = `<!-- default.tmpl -->
 <article>
+    {{ with .Authors }}<p class="byline">{{ T "By" }} {{ range $i, $author := . }}{{ if $i }}, {{ end }}{{ if .URL }}<a href="{{ .URL }}" rel="author">{{ .Name }}</a>{{ else }}{{ .Name }}{{ end }}{{ end }}</p>{{ end }}
     <div>{{ .Content }}</div>
 </article>
+{{ with .Series }}
+<nav class="series">
+    <p>{{ T "Part %d of %d" .Position .Total }} - {{ if .URL }}<a href="{{ .URL }}">{{ .Name }}</a>{{ else }}{{ .Name }}{{ end }}</p>
+    {{ with .Prev }}<a href="{{ .OutputPath }}" rel="prev">&larr; {{ or .MetaData.title .Name }}</a>{{ end }}
+    {{ with .Next }}<a href="{{ .OutputPath }}" rel="next">{{ or .MetaData.title .Name }} &rarr;</a>{{ end }}
+</nav>
+{{ end }}
+{{ with .Related }}
+<aside class="related">
+    <h2>{{ T "Related" }}</h2>
+    <ul>
+        {{ range . }}
+        <li><a href="{{ .OutputPath }}">{{ or .MetaData.title .Name }}</a></li>
+        {{ end }}
+    </ul>
+</aside>
+{{ end }}
 `
 
 const ListTemplate_bootstrap = `<!-- list.tmpl -->
@@ -18,19 +36,26 @@ const ListTemplate_bootstrap = `<!-- list.tmpl -->
 
 const PageTemplate_bootstrap = `<!-- fullpage.tmpl -->
 <!DOCTYPE html>
-<html lang="en">
+<html lang="{{ .Page.Language }}">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <link href="https://cdn.jsdelivr.net/npm/bootstrap@5.3.2/dist/css/bootstrap.min.css" rel="stylesheet" integrity="sha384-T3c6CoIi6uLrA9TneNEoa7RxnatzjcDSCmG1MXxSR1GAsXEV/Dwwykc2MPK8M2HN" crossorigin="anonymous">
     <script src="https://cdn.jsdelivr.net/npm/bootstrap@5.3.2/dist/js/bootstrap.bundle.min.js" integrity="sha384-C6RzsynM9kWDrMNeT87bh95OGNyZPhcTNXj1NW7RuBCsyN/o0jlpcV8Qyq46cDfL" crossorigin="anonymous"></script>
-    <title>{{ .Title }}</title>
+    {{ block "head" . }}<title>{{ .Title }}</title>{{ end }}
+    {{ partial "seo" . }}
+    {{ with .Page.Translations }}
+    <link rel="alternate" hreflang="{{ $.Page.Language }}" href="{{ absURL $.Page.OutputPath }}">
+    {{ range . }}
+    <link rel="alternate" hreflang="{{ .Language }}" href="{{ absURL .OutputPath }}">
+    {{ end }}
+    {{ end }}
     <link rel="stylesheet" href="/asset/css/styles.css">
 </head>
 <body>
     {{ template "header.tmpl" . }}
     <main class="main container">
-        {{ .Content }}
+        {{ block "main" . }}{{ .Content }}{{ end }}
     </main>
     {{ template "footer.tmpl" . }}
 </body>
@@ -40,7 +65,7 @@ const PageTemplate_bootstrap = `<!-- fullpage.tmpl -->
 const HeaderTemplate_bootstrap = `<!-- header.tmpl -->
 <div class="px-4">
     <header class="d-flex flex-wrap justify-content-center py-3 mb-4 border-bottom">
-      <a href="/" class="d-flex align-items-center mb-3 mb-md-0 me-md-auto text-dark text-decoration-none">
+      <a href="{{ .Site.Home }}" class="d-flex align-items-center mb-3 mb-md-0 me-md-auto text-dark text-decoration-none">
         {{ .Logo }}  
         <span class="fs-4 mx-3">{{ .Title }}</span>
       </a>
@@ -52,9 +77,23 @@ const HeaderTemplate_bootstrap = `<!-- header.tmpl -->
 
 const NavigationTemplate_bootstrap = `<!-- navigation.tmpl -->
 <ul class="nav nav-pills">
-    <li class="nav-item"><a href="/" class="nav-link">Home</a></li>
-    <li class="nav-item"><a href="/test.html" class="nav-link">Test page</a></li>
-    <li class="nav-item"><a href="#" class="nav-link">About</a></li>
+    {{ range .Site.Menus.main }}
+    {{ if .Children }}
+    <li class="nav-item dropdown">
+        <a href="{{ .URL }}" class="nav-link dropdown-toggle{{ if .HasActiveChild $.Page }} active{{ end }}" data-bs-toggle="dropdown">{{ .Name }}</a>
+        <ul class="dropdown-menu">
+            {{ range .Children }}
+            <li><a href="{{ .URL }}" class="dropdown-item{{ if .IsActive $.Page }} active{{ end }}">{{ .Name }}</a></li>
+            {{ end }}
+        </ul>
+    </li>
+    {{ else }}
+    <li class="nav-item"><a href="{{ .URL }}" class="nav-link{{ if .IsActive $.Page }} active{{ end }}"{{ if .IsActive $.Page }} aria-current="page"{{ end }}>{{ .Name }}</a></li>
+    {{ end }}
+    {{ end }}
+    {{ range .Page.Translations }}
+    <li class="nav-item"><a href="{{ .OutputPath }}" class="nav-link" hreflang="{{ .Language }}" lang="{{ .Language }}">{{ .LanguageName }}</a></li>
+    {{ end }}
 </ul>
 `
 
@@ -2,8 +2,26 @@ package main
 
 const DefaultTemplate_pico = `<!-- default.tmpl -->
 <article>
+    {{ with .Authors }}<p class="byline">{{ T "By" }} {{ range $i, $author := . }}{{ if $i }}, {{ end }}{{ if .URL }}<a href="{{ .URL }}" rel="author">{{ .Name }}</a>{{ else }}{{ .Name }}{{ end }}{{ end }}</p>{{ end }}
     <div>{{ .Content }}</div>
 </article>
+{{ with .Series }}
+<nav class="series">
+    <p>{{ T "Part %d of %d" .Position .Total }} - {{ if .URL }}<a href="{{ .URL }}">{{ .Name }}</a>{{ else }}{{ .Name }}{{ end }}</p>
+    {{ with .Prev }}<a href="{{ .OutputPath }}" rel="prev">&larr; {{ or .MetaData.title .Name }}</a>{{ end }}
+    {{ with .Next }}<a href="{{ .OutputPath }}" rel="next">{{ or .MetaData.title .Name }} &rarr;</a>{{ end }}
+</nav>
+{{ end }}
+{{ with .Related }}
+<aside class="related">
+    <h2>{{ T "Related" }}</h2>
+    <ul>
+        {{ range . }}
+        <li><a href="{{ .OutputPath }}">{{ or .MetaData.title .Name }}</a></li>
+        {{ end }}
+    </ul>
+</aside>
+{{ end }}
 `
 
 const ListTemplate_pico = `<!-- list.tmpl -->
@@ -18,18 +36,25 @@ const ListTemplate_pico = `<!-- list.tmpl -->
 
 const PageTemplate_pico = `<!-- fullpage.tmpl -->
 <!DOCTYPE html>
-<html lang="en">
+<html lang="{{ .Page.Language }}">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>{{ .Title }}</title>
+    {{ block "head" . }}<title>{{ .Title }}</title>{{ end }}
+    {{ partial "seo" . }}
+    {{ with .Page.Translations }}
+    <link rel="alternate" hreflang="{{ $.Page.Language }}" href="{{ absURL $.Page.OutputPath }}">
+    {{ range . }}
+    <link rel="alternate" hreflang="{{ .Language }}" href="{{ absURL .OutputPath }}">
+    {{ end }}
+    {{ end }}
     <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/@picocss/pico@1/css/pico.min.css">
     <link rel="stylesheet" href="/asset/css/styles.css">
 </head>
 <body>
     {{ template "header.tmpl" . }}
     <div class="main container">
-        {{ .Content }}
+        {{ block "main" . }}{{ .Content }}{{ end }}
     </div>
     {{ template "footer.tmpl" . }}
 </body>
@@ -39,7 +64,7 @@ const PageTemplate_pico = `<!-- fullpage.tmpl -->
 const HeaderTemplate_pico = `<!-- header.tmpl -->
 <nav class="container-fluid">
     <ul>
-        <li><a href="/" aria-label="Back home">
+        <li><a href="{{ .Site.Home }}" aria-label="Back home">
             {{ .Logo }}
             </a>
         </li>
@@ -51,9 +76,25 @@ const HeaderTemplate_pico = `<!-- header.tmpl -->
 
 const NavigationTemplate_pico = `<!-- navigation.tmpl -->
     <ul>
-        <li><a href="/">Home</a></li>
-        <li><a href="/test.html">Test page</a></li>
-        <li><a href="#">About Us</a></li>
+        {{ range .Site.Menus.main }}
+        {{ if .Children }}
+        <li>
+            <details class="dropdown">
+                <summary>{{ .Name }}</summary>
+                <ul>
+                    {{ range .Children }}
+                    <li><a href="{{ .URL }}"{{ if .IsActive $.Page }} aria-current="page"{{ end }}>{{ .Name }}</a></li>
+                    {{ end }}
+                </ul>
+            </details>
+        </li>
+        {{ else }}
+        <li><a href="{{ .URL }}"{{ if .IsActive $.Page }} aria-current="page"{{ end }}>{{ .Name }}</a></li>
+        {{ end }}
+        {{ end }}
+        {{ range .Page.Translations }}
+        <li><a href="{{ .OutputPath }}" hreflang="{{ .Language }}" lang="{{ .Language }}">{{ .LanguageName }}</a></li>
+        {{ end }}
     </ul>
 `
 
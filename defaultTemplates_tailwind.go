@@ -2,8 +2,26 @@ package main
 
 const DefaultTemplate_tailwind = `<!-- default.tmpl -->
 <article>
+    {{ with .Authors }}<p class="byline">{{ T "By" }} {{ range $i, $author := . }}{{ if $i }}, {{ end }}{{ if .URL }}<a href="{{ .URL }}" rel="author">{{ .Name }}</a>{{ else }}{{ .Name }}{{ end }}{{ end }}</p>{{ end }}
     <div>{{ .Content }}</div>
 </article>
+{{ with .Series }}
+<nav class="series">
+    <p>{{ T "Part %d of %d" .Position .Total }} - {{ if .URL }}<a href="{{ .URL }}">{{ .Name }}</a>{{ else }}{{ .Name }}{{ end }}</p>
+    {{ with .Prev }}<a href="{{ .OutputPath }}" rel="prev">&larr; {{ or .MetaData.title .Name }}</a>{{ end }}
+    {{ with .Next }}<a href="{{ .OutputPath }}" rel="next">{{ or .MetaData.title .Name }} &rarr;</a>{{ end }}
+</nav>
+{{ end }}
+{{ with .Related }}
+<aside class="related">
+    <h2>{{ T "Related" }}</h2>
+    <ul>
+        {{ range . }}
+        <li><a href="{{ .OutputPath }}">{{ or .MetaData.title .Name }}</a></li>
+        {{ end }}
+    </ul>
+</aside>
+{{ end }}
 `
 
 const ListTemplate_tailwind = `<!-- list.tmpl -->
@@ -18,19 +36,26 @@ const ListTemplate_tailwind = `<!-- list.tmpl -->
 
 const PageTemplate_tailwind = `<!-- fullpage.tmpl -->
 <!DOCTYPE html>
-<html lang="en">
+<html lang="{{ .Page.Language }}">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <script src="https://cdn.tailwindcss.com"></script>
-    <title>{{ .Title }}</title>
+    {{ block "head" . }}<title>{{ .Title }}</title>{{ end }}
+    {{ partial "seo" . }}
+    {{ with .Page.Translations }}
+    <link rel="alternate" hreflang="{{ $.Page.Language }}" href="{{ absURL $.Page.OutputPath }}">
+    {{ range . }}
+    <link rel="alternate" hreflang="{{ .Language }}" href="{{ absURL .OutputPath }}">
+    {{ end }}
+    {{ end }}
     <link rel="stylesheet" href="/asset/css/styles.css">
 </head>
 <body>
     {{ template "header.tmpl" . }}
     {{ template "navigation.tmpl" . }}
     <div class="main container">
-        {{ .Content }}
+        {{ block "main" . }}{{ .Content }}{{ end }}
     </div>
     {{ template "footer.tmpl" . }}
 </body>
@@ -47,9 +72,21 @@ const HeaderTemplate_tailwind = `<!-- header.tmpl -->
 const NavigationTemplate_tailwind = `<!-- navigation.tmpl -->
 <nav>
     <ul>
-        <li><a href="/">Home</a></li>
-        <li><a href="/about">About Us</a></li>
-        <li><a href="/contact">Contact</a></li>
+        {{ range .Site.Menus.main }}
+        <li>
+            <a href="{{ .URL }}"{{ if .IsActive $.Page }} aria-current="page"{{ end }}>{{ .Name }}</a>
+            {{ if .Children }}
+            <ul>
+                {{ range .Children }}
+                <li><a href="{{ .URL }}"{{ if .IsActive $.Page }} aria-current="page"{{ end }}>{{ .Name }}</a></li>
+                {{ end }}
+            </ul>
+            {{ end }}
+        </li>
+        {{ end }}
+        {{ range .Page.Translations }}
+        <li><a href="{{ .OutputPath }}" hreflang="{{ .Language }}" lang="{{ .Language }}">{{ .LanguageName }}</a></li>
+        {{ end }}
     </ul>
 </nav>
 `
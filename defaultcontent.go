@@ -15,10 +15,13 @@ const HelpText = `Repose Commands:
 Usage: repose [OPTIONS] <COMMAND>
 
 Commands:
-	init    - Initialize a new Repose project
+	init    - Initialize a new Repose project. Usage: repose init [--sitename NAME] [--author NAME] [--theme THEME] [--url URL] [--editor EDITOR] [--yes] [--force] [--from DIR|TARBALL]
 	new     - Create new content. Usage: repose new [CONTENTTYPE] [FILENAME]
-	build   - Build the site.
+	build   - Build the site. Usage: repose build [--fail-fast]
 	preview - Setup a local server to preview the site
+	check   - Check the built site for broken links. Usage: repose check [--format json] [--external]
+	lint    - Check the front matter of the content against the content type schemas
+	mv      - Move content and redirect its old URL. Usage: repose mv [--dry-run] [SOURCE] [DEST]
 	help    - Show this help message 
 	
 Options:
@@ -50,6 +53,23 @@ template: {contentType}.tmpl
 
 `
 
+// The default archetype written by init, used by repose new for content types
+// without their own archetype
+const DefaultArchetype = `---
+title: {{ .Title }}
+description: {{ .Section }} about {{ .Title }}
+tags: []
+image: 
+index: true
+author: {{ .Author }}
+publish_date: {{ .Date }}
+template: {{ .Section }}.tmpl
+---
+
+# {{ .Title }}
+
+`
+
 const MarkdownTest = `
 ---
 title: Markdown Test Page
@@ -60,6 +80,10 @@ index: true
 author: Creator
 publish_date: 
 template: default.tmpl
+menu:
+  main:
+    weight: 2
+    title: Test page
 ---
 
 # h1 Heading
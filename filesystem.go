@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
 	"os"
@@ -109,44 +108,6 @@ func (f *Filesystem) IsDir(path string) (bool, error) {
 	return fileInfo.IsDir(), nil
 }
 
-// Basic YAML format parser that reads the content & returns a map of the data.
-// We use this instead of loading the YAML modules to keep the size down
-func (f *Filesystem) ParseYml(content string) (map[string]string, error) {
-	// Check for empty content
-	if content == "" {
-		return nil, errors.New("parsing failed: empty yaml content")
-	}
-
-	scanner := bufio.NewScanner(strings.NewReader(content))
-	ymlMap := make(map[string]string)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) != 2 {
-			// Log a warning for invalid lines
-			logger.Warn("Invalid YAML line:", line)
-			continue // Skip invalid lines
-		}
-
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-
-		ymlMap[key] = value
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-
-	// Check for empty parsed map, indicating potential parsing issues
-	if len(ymlMap) == 0 {
-		return nil, errors.New("parsing failed: invalid yaml structure")
-	}
-
-	return ymlMap, nil
-}
-
 // Get information about the file at the given path.
 func (f *Filesystem) GetFileInfo(rootDir string, path string) (relPath string, dir string, firstSubdir string, fileName string, extension string, err error) {
 	// Check if the rootDir is a directory
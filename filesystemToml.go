@@ -0,0 +1,59 @@
+package main
+
+import (
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// The layouts of the TOML dates and times without an offset, keyed by the
+// name of the zone the TOML decoder gives them
+var tomlLocalLayouts = map[string]string{
+	"datetime-local": "2006-01-02T15:04:05.999999999",
+	"date-local":     "2006-01-02",
+	"time-local":     "15:04:05.999999999",
+}
+
+// **********  Public Filesystem methods  **********
+
+// Parse TOML content & return a map of the data.
+// Values are returned the same way the YAML parser returns them, so front
+// matter and data files work the same in either format: whole numbers are
+// int, dates are strings and arrays of tables are lists of maps.
+func (f *Filesystem) ParseToml(content string) (map[string]interface{}, error) {
+	data := make(map[string]interface{})
+	if _, err := toml.Decode(content, &data); err != nil {
+		return nil, err
+	}
+	return tomlValues(data).(map[string]interface{}), nil
+}
+
+// **********  Private TOML methods  **********
+
+// Convert the values from the TOML decoder to the types the YAML parser uses
+func tomlValues(value interface{}) interface{} {
+	switch value := value.(type) {
+	case int64:
+		return int(value)
+	case time.Time:
+		if layout, isLocal := tomlLocalLayouts[value.Location().String()]; isLocal {
+			return value.Format(layout)
+		}
+		return value.Format(time.RFC3339Nano)
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = tomlValues(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = tomlValues(item)
+		}
+	case []map[string]interface{}:
+		items := make([]interface{}, len(value))
+		for i, item := range value {
+			items[i] = tomlValues(item)
+		}
+		return items
+	}
+	return value
+}
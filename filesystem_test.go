@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -65,3 +66,71 @@ func TestFilesystem_Delete(t *testing.T) {
 		t.Errorf("Directory still exists after deletion")
 	}
 }
+
+func TestFilesystem_ParseToml(t *testing.T) {
+	content := `# Site data
+title = "Repose \"site\""
+path = 'C:\content'
+count = 1_000
+ratio = 0.5
+draft = false
+date = 2024-01-15
+published = 1979-05-27 07:32:00Z
+tags = [ "go", "static",
+  "site", ]
+author = { name = "Creator", links = ["a", "b"] }
+site.theme.name = "pico"
+bio = """
+Line one \
+  continued"""
+
+[params]
+color = "blue"
+
+[[products]]
+name = "Widget"
+
+[[products]]
+name = "Gadget"
+sizes.small = 1
+`
+	data, err := filesystem.ParseToml(content)
+	if err != nil {
+		t.Fatalf("ParseToml returned an error: %v", err)
+	}
+
+	tests := []struct {
+		got  interface{}
+		want interface{}
+	}{
+		{data["title"], `Repose "site"`},
+		{data["path"], `C:\content`},
+		{data["count"], 1000},
+		{data["ratio"], 0.5},
+		{data["draft"], false},
+		{data["date"], "2024-01-15"},
+		{data["published"], "1979-05-27T07:32:00Z"},
+		{len(data["tags"].([]interface{})), 3},
+		{data["author"].(map[string]interface{})["name"], "Creator"},
+		{data["site"].(map[string]interface{})["theme"].(map[string]interface{})["name"], "pico"},
+		{data["bio"], "Line one continued"},
+		{data["params"].(map[string]interface{})["color"], "blue"},
+		{len(data["products"].([]interface{})), 2},
+		{data["products"].([]interface{})[1].(map[string]interface{})["name"], "Gadget"},
+	}
+	for i, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("Value %d mismatch. Got: %v, Want: %v", i, tt.got, tt.want)
+		}
+	}
+
+	// Errors point at the line
+	_, err = filesystem.ParseToml("title = \"ok\"\n\nname = \"unterminated\n")
+	if err == nil || !strings.HasPrefix(err.Error(), "toml: line 3") {
+		t.Errorf("Error mismatch. Got: %v", err)
+	}
+	_, err = filesystem.ParseToml("a = 1\na = 2\n")
+	if err == nil {
+		t.Error("Expected an error for a duplicate key")
+	}
+}
@@ -5,8 +5,8 @@ go 1.21.6
 require golang.org/x/text v0.14.0
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/yuin/goldmark v1.7.0
 	github.com/yuin/goldmark-meta v1.1.0
+	gopkg.in/yaml.v2 v2.3.0
 )
-
-require gopkg.in/yaml.v2 v2.3.0 // indirect
@@ -29,7 +29,7 @@ func dispatchCommand(commandName string) {
 
 	// Load config for specific commands
 	switch commandName {
-	case "new", "build", "preview":
+	case "new", "build", "preview", "check", "lint", "mv":
 		var err error
 		config, err = config.Load()
 		if err != nil {
@@ -55,6 +55,12 @@ func dispatchCommand(commandName string) {
 		command.Build(config)
 	case "preview":
 		command.Preview(config)
+	case "check":
+		command.Check(config)
+	case "lint":
+		command.Lint(config)
+	case "mv":
+		command.Move(config)
 	case "update":
 		command.Update()
 	case "help":